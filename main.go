@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,17 +21,39 @@ import (
 	"github.com/md-salehzadeh/dbun/src/ui"
 )
 
+// pageSize is the number of rows fetched per page in the Data view
+const pageSize = 100
+
 // AppModel represents the application state
 type AppModel struct {
 	// Database & data
-	dbManager     *db.Manager
-	dbConfig      config.DBConfig
-	tables        []string
-	tableData     map[string][]model.RowData
-	tableMetadata map[string][]model.ColumnMetadata
-	tableIndices  map[string][]string
-	connected     bool
-	errorMsg      string
+	dbManager        *db.Manager
+	dbConfig         config.DBConfig
+	generation       int // Counts database switches, to drop results from earlier connections
+	tables           []string
+	tableTypes       map[string]model.TableType
+	objects          []model.SchemaObject // Procedures, functions, triggers and events
+	tableData        map[string][]model.RowData
+	tableMetadata    map[string][]model.ColumnMetadata
+	tableIndices     map[string][]model.IndexMetadata
+	tableForeignKeys map[string][]model.ForeignKey
+	tableDDL         map[string]string
+	connected        bool
+	errorMsg         string
+	statusMessage    string
+	startupCmd       tea.Cmd // Initial background work, returned from Init
+
+	// Pagination state per table
+	tableOffset      map[string]int   // Offset of the first loaded row
+	tableHasMore     map[string]bool  // Whether rows exist past the loaded page
+	tableRowEstimate map[string]int64 // Approximate total row count
+	tableLoading     map[string]bool  // Tables with a background load in flight
+	tableFilter      map[string]model.RowFilter
+	tableSort        map[string][]model.SortColumn
+	loadCancels      map[string]context.CancelFunc
+	loadIDs          map[string]int // Number of the load in flight per table
+	loadSeq          int            // Last load number handed out, never reused
+	spinnerFrame     int
 
 	// UI state
 	styles         ui.Styles
@@ -34,6 +63,9 @@ type AppModel struct {
 	width          int
 	height         int
 	focusLeft      bool
+	relationIdx    int                 // Selected foreign key in the Relations view
+	activeObject   *model.SchemaObject // Object shown instead of the active table, if any
+	navHistory     []navEntry
 
 	// Scroll state
 	sidebarScroll int
@@ -49,34 +81,91 @@ type AppModel struct {
 	showEditModal  bool // Flag for modal visibility
 	modalTargetRow int  // Target row for modal edit
 	modalTargetCol int  // Target col for modal edit
-	
+
+	// Staged changes state
+	pendingChanges map[string][]model.Change // Uncommitted changes per table
+	showReview     bool                      // Flag for change review modal visibility
+	reviewScroll   int
+	confirmQuit    bool // Set after a quit attempt with uncommitted changes
+	confirmDelete  bool // Waiting for y/n on a row deletion
+	committing     bool // Staged changes are being written in the background
+	commitCancel   context.CancelFunc
+
+	// Query editor state
+	queryBuffer  string
+	queryEditing bool
+	queryRunning bool
+	queryCancel  context.CancelFunc
+	queryResult  *model.QueryResult
+	queryStatus  string // Outcome of the last query, shown under the editor
+
+	// Row filter prompt state
+	rowFilterPrompt  rowFilterSyntax // Syntax of the row filter being typed, empty when closed
+	rowFilterBuffer  string
+	rowFilterRestore map[string]model.RowFilter // Filter to restore if a newly applied one fails
+
+	// Data search state
+	dataSearching bool   // Whether a search term is being typed in the Data view
+	dataSearch    string // Term whose matching cells are highlighted in the Data view
+
+	// Export prompt state
+	exportPrompt bool // Whether the file name of an export is being typed
+	exportBuffer string
+	exporting    bool // An export is being written in the background
+	exportCancel context.CancelFunc
+
+	// Database switcher state
+	showDatabasePicker bool
+	databases          []string
+	databaseIdx        int
+	listingDatabases   bool
+	switchingDatabase  bool
+	databaseCancel     context.CancelFunc // Cancels listing or switching databases
+
 	// Filtering state
-	filtering        bool      // Whether filtering is active
-	filterBuffer     string    // Filter input text
-	filteredTables   []string  // List of tables that match the filter
-	matchPositions   map[string][]int // Positions of matched characters for highlighting
+	filtering       bool                  // Whether filtering is active
+	filterBuffer    string                // Filter input text
+	filteredTables  []string              // List of tables that match the filter
+	filteredObjects []*model.SchemaObject // Other objects that match the filter
+	matchPositions  map[string][]int      // Positions of matched characters for highlighting
 }
 
-// Initialize the app model with database connection
-func NewAppModel() (AppModel, error) {
-	// Load database configuration
-	dbConfig := config.LoadConfig()
-
-	m := AppModel{
-		dbConfig:       dbConfig,
-		tables:         []string{},
-		selectedIdx:    0,
-		activeTableIdx: 0,
-		mode:           model.DataMode,
-		width:          80,
-		height:         24,
-		focusLeft:      true,
-		tableData:      make(map[string][]model.RowData),
-		tableMetadata:  make(map[string][]model.ColumnMetadata),
-		tableIndices:   make(map[string][]string),
-		matchPositions: make(map[string][]int),
-		connected:      false,
+// newAppModel creates an app model that is not yet attached to a database
+func newAppModel(dbConfig config.DBConfig) AppModel {
+	return AppModel{
+		dbConfig:         dbConfig,
+		tables:           []string{},
+		tableTypes:       make(map[string]model.TableType),
+		selectedIdx:      0,
+		activeTableIdx:   0,
+		mode:             model.DataMode,
+		width:            80,
+		height:           24,
+		focusLeft:        true,
+		tableData:        make(map[string][]model.RowData),
+		tableMetadata:    make(map[string][]model.ColumnMetadata),
+		tableIndices:     make(map[string][]model.IndexMetadata),
+		tableForeignKeys: make(map[string][]model.ForeignKey),
+		tableDDL:         make(map[string]string),
+		matchPositions:   make(map[string][]int),
+		pendingChanges:   make(map[string][]model.Change),
+		connected:        false,
+
+		tableOffset:      make(map[string]int),
+		tableHasMore:     make(map[string]bool),
+		tableRowEstimate: make(map[string]int64),
+		tableLoading:     make(map[string]bool),
+		tableFilter:      make(map[string]model.RowFilter),
+		tableSort:        make(map[string][]model.SortColumn),
+		rowFilterRestore: make(map[string]model.RowFilter),
+		loadCancels:      make(map[string]context.CancelFunc),
+		loadIDs:          make(map[string]int),
 	}
+}
+
+// Initialize the app model with database connection
+func NewAppModel(dbConfig config.DBConfig) (AppModel, error) {
+	m := newAppModel(dbConfig)
 
 	// Connect to database
 	dbm, err := db.NewManager(dbConfig)
@@ -84,138 +173,146 @@ func NewAppModel() (AppModel, error) {
 		return m, err
 	}
 
+	err = m.attach(dbm)
+	return m, err
+}
+
+// Fallback to the embedded demo database when the database connection fails
+func NewDemoAppModel(dbConfig config.DBConfig) (AppModel, error) {
+	m := newAppModel(dbConfig)
+
+	dbm, err := db.NewDemoManager(dbConfig)
+	if err != nil {
+		return m, err
+	}
+
+	err = m.attach(dbm)
+	return m, err
+}
+
+// attach connects the model to a database manager and starts loading the first table
+func (m *AppModel) attach(dbm *db.Manager) error {
 	m.dbManager = dbm
 	m.connected = true
 
 	// Fetch table names
-	tables, err := dbm.GetTableNames()
+	ctx, cancel := m.newContext()
+	defer cancel()
+	tables, err := dbm.GetTables(ctx)
 	if err != nil {
-		return m, err
+		return err
 	}
 
-	m.tables = tables
+	objects, err := dbm.GetObjects(ctx)
+	if err != nil {
+		return err
+	}
 
-	// Pre-fetch metadata for all tables
-	for _, table := range tables {
-		// Fetch metadata
-		metadata, err := dbm.GetTableMetadata(table)
-		if err != nil {
-			return m, err
-		}
-		m.tableMetadata[table] = metadata
+	m.setTables(tables)
+	m.objects = objects
+	m.selectSidebarRow(0, 1)
 
-		// Fetch indices
-		indices, err := dbm.GetTableIndices(table)
-		if err != nil {
-			return m, err
-		}
-		m.tableIndices[table] = indices
+	// Tables are loaded lazily when activated; start with the first one
+	if len(tables) > 0 {
+		m.startupCmd = m.activateTable(0)
+	}
 
-		// Fetch data (limited to 100 rows per table)
-		data, err := dbm.GetTableData(table, 100)
-		if err != nil {
-			return m, err
+	return nil
+}
+
+// setTables replaces the table list, remembering the type of each table
+func (m *AppModel) setTables(tables []model.TableInfo) {
+	m.tables = make([]string, len(tables))
+	m.tableTypes = make(map[string]model.TableType, len(tables))
+	for i, table := range tables {
+		m.tables[i] = table.Name
+		m.tableTypes[table.Name] = table.Type
+	}
+}
+
+// profilePicker is the startup screen for choosing a connection profile
+type profilePicker struct {
+	profiles    []config.DBConfig
+	selectedIdx int
+	chosen      *config.DBConfig
+	width       int
+	height      int
+}
+
+func (p *profilePicker) Init() tea.Cmd {
+	return nil
+}
+
+func (p *profilePicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			if p.selectedIdx > 0 {
+				p.selectedIdx--
+			}
+		case "down", "j":
+			if p.selectedIdx < len(p.profiles)-1 {
+				p.selectedIdx++
+			}
+		case "enter":
+			p.chosen = &p.profiles[p.selectedIdx]
+			return p, tea.Quit
+		case "q", "esc", "ctrl+c":
+			return p, tea.Quit
 		}
-		m.tableData[table] = data
 	}
+	return p, nil
+}
 
-	return m, nil
+func (p *profilePicker) View() string {
+	names := make([]string, len(p.profiles))
+	summaries := make([]string, len(p.profiles))
+	for i, profile := range p.profiles {
+		names[i] = profile.Name
+		summaries[i] = profile.Summary()
+	}
+	return ui.RenderProfilePicker(ui.NewStyles(p.width, p.height), p.width, p.height, names, summaries, p.selectedIdx)
 }
 
-// Fallback to sample data when database connection fails
-func NewAppModelWithSampleData() AppModel {
-	// Generate sample data
-	users, orders, products, categories := model.GenerateSampleData()
-
-	m := AppModel{
-		tables:         []string{"users", "orders", "products", "categories"},
-		selectedIdx:    0,
-		activeTableIdx: 0,
-		mode:           model.DataMode,
-		width:          80,
-		height:         24,
-		focusLeft:      true,
-		connected:      true, // Pretend we're connected even though we're using sample data
-		tableData:      make(map[string][]model.RowData),
-		tableMetadata:  make(map[string][]model.ColumnMetadata),
-		tableIndices:   make(map[string][]string),
-		matchPositions: make(map[string][]int),
-	}
-
-	// Convert sample data to RowData format
-	userRows := make([]model.RowData, len(users))
-	for i, user := range users {
-		userRows[i] = model.RowData{
-			"ID":       user.ID,
-			"Username": user.Username,
-			"Email":    user.Email,
-			"Active":   user.Active,
-		}
-	}
-	m.tableData["users"] = userRows
-	m.tableMetadata["users"] = []model.ColumnMetadata{
-		{Name: "ID", Type: "int", Nullable: false, Key: "PRI"},
-		{Name: "Username", Type: "varchar(50)", Nullable: false, Key: "UNI"},
-		{Name: "Email", Type: "varchar(100)", Nullable: false, Key: "UNI"},
-		{Name: "Active", Type: "tinyint(1)", Nullable: false, Key: ""},
-	}
-	m.tableIndices["users"] = []string{"PRIMARY", "idx_username", "idx_email"}
-
-	// Convert other sample data to RowData format similarly
-	orderRows := make([]model.RowData, len(orders))
-	for i, order := range orders {
-		orderRows[i] = model.RowData{
-			"ID":         order.ID,
-			"UserID":     order.UserID,
-			"TotalPrice": order.TotalPrice,
-			"Status":     order.Status,
-		}
-	}
-	m.tableData["orders"] = orderRows
-	m.tableMetadata["orders"] = []model.ColumnMetadata{
-		{Name: "ID", Type: "int", Nullable: false, Key: "PRI"},
-		{Name: "UserID", Type: "int", Nullable: false, Key: "MUL"},
-		{Name: "TotalPrice", Type: "decimal(10,2)", Nullable: false, Key: ""},
-		{Name: "Status", Type: "varchar(20)", Nullable: false, Key: ""},
-	}
-	m.tableIndices["orders"] = []string{"PRIMARY", "idx_user_id"}
-
-	// Add products and categories similarly
-	productRows := make([]model.RowData, len(products))
-	for i, product := range products {
-		productRows[i] = model.RowData{
-			"ID":       product.ID,
-			"Name":     product.Name,
-			"Price":    product.Price,
-			"Category": product.Category,
-		}
-	}
-	m.tableData["products"] = productRows
-	m.tableMetadata["products"] = []model.ColumnMetadata{
-		{Name: "ID", Type: "int", Nullable: false, Key: "PRI"},
-		{Name: "Name", Type: "varchar(100)", Nullable: false, Key: ""},
-		{Name: "Price", Type: "decimal(10,2)", Nullable: false, Key: ""},
-		{Name: "Category", Type: "varchar(50)", Nullable: false, Key: "MUL"},
-	}
-	m.tableIndices["products"] = []string{"PRIMARY", "idx_category"}
-
-	categoryRows := make([]model.RowData, len(categories))
-	for i, category := range categories {
-		categoryRows[i] = model.RowData{
-			"ID":   category.ID,
-			"Name": category.Name,
-			"Slug": category.Slug,
-		}
-	}
-	m.tableData["categories"] = categoryRows
-	m.tableMetadata["categories"] = []model.ColumnMetadata{
-		{Name: "ID", Type: "int", Nullable: false, Key: "PRI"},
-		{Name: "Name", Type: "varchar(50)", Nullable: false, Key: ""},
-		{Name: "Slug", Type: "varchar(50)", Nullable: false, Key: "UNI"},
-	}
-	m.tableIndices["categories"] = []string{"PRIMARY", "idx_slug"}
+// selectProfile decides which profile to connect with: the one named on the command
+// line, the only or default one from the config file, or one picked interactively.
+// It returns nil when the environment alone should configure the connection.
+func selectProfile(name string) (*config.DBConfig, error) {
+	profiles, err := config.LoadProfiles(config.DefaultPath())
+	if err != nil {
+		return nil, err
+	}
 
-	return m
+	if name == "" {
+		name = profiles.Default
+	}
+	if name != "" {
+		profile, ok := profiles.Find(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %s", name)
+		}
+		return &profile, nil
+	}
+
+	switch len(profiles.Profiles) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &profiles.Profiles[0], nil
+	}
+
+	picker := &profilePicker{profiles: profiles.Profiles, width: 80, height: 24}
+	if _, err := tea.NewProgram(picker, tea.WithAltScreen()).Run(); err != nil {
+		return nil, err
+	}
+	if picker.chosen == nil {
+		os.Exit(0)
+	}
+	return picker.chosen, nil
 }
 
 // Clean up resources when the application exits
@@ -225,190 +322,1053 @@ func (m *AppModel) Close() {
 	}
 }
 
-// Tea model interface implementation
-func (m AppModel) Init() tea.Cmd {
-	return nil
+// navEntry remembers where the user was before following a foreign key
+type navEntry struct {
+	table      string
+	filter     model.RowFilter
+	mode       model.ViewMode
+	offset     int
+	cursorRow  int
+	cursorCol  int
+	mainScroll int
 }
 
-func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.styles = ui.NewStyles(m.width, m.height)
-		return m, nil
+// cursorPlacement tells where to put the data cursor once a page has loaded
+type cursorPlacement int
 
-	case tea.KeyMsg:
-		return m.handleKeyPress(msg)
-	}
+const (
+	placeKeep cursorPlacement = iota
+	placeTop
+	placeBottom
+)
 
-	return m, nil
+// lastPage requests the final page of a table in place of an offset
+const lastPage = -1
+
+// tableLoadedMsg carries the result of loading a table in the background
+type tableLoadedMsg struct {
+	table       string
+	load        int
+	metadata    []model.ColumnMetadata
+	indices     []model.IndexMetadata
+	foreignKeys []model.ForeignKey
+	data        []model.RowData
+	estimate    int64
+	err         error
 }
 
-// Handle key presses based on current state
-func (m *AppModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle modal input first if it's active
-	if m.showEditModal {
-		return m.handleEditModalKeys(msg)
-	}
+// pageLoadedMsg carries one page of table rows fetched in the background
+type pageLoadedMsg struct {
+	table     string
+	load      int
+	offset    int
+	data      []model.RowData
+	estimate  int64
+	placement cursorPlacement
+	err       error
+}
 
-	// Handle filtering mode if it's active
-	if m.filtering && m.focusLeft {
-		return m.handleFilterModeKeys(msg)
-	}
+// ddlLoadedMsg carries the create statement of a table
+type ddlLoadedMsg struct {
+	table string
+	load  int
+	ddl   string
+	err   error
+}
 
-	// Handle global keys first
-	switch msg.String() {
-	case "ctrl+c", "q":
-		return m, tea.Quit // Exit app
+// queryResultMsg carries the outcome of a statement run from the Query view
+type queryResultMsg struct {
+	generation int
+	result     *model.QueryResult
+	err        error
+}
 
-	case "tab":
-		// Toggle focus between left and right boxes
-		m.focusLeft = !m.focusLeft
-		// Reset editing state when switching focus
-		if m.focusLeft && m.editing {
-			m.editing = false
-			m.editBuffer = ""
+// databaseSwitchedMsg carries the manager and table list of a newly selected database
+type databaseSwitchedMsg struct {
+	name    string
+	manager *db.Manager
+	tables  []model.TableInfo
+	objects []model.SchemaObject
+	err     error
+}
+
+// changesCommittedMsg reports the outcome of writing the staged changes
+type changesCommittedMsg struct {
+	changes  []model.Change
+	affected int64
+	err      error
+}
+
+// databasesListedMsg carries the databases on the server for the switcher
+type databasesListedMsg struct {
+	databases []string
+	err       error
+}
+
+// exportDoneMsg reports the outcome of an export
+type exportDoneMsg struct {
+	path string
+	rows int64
+	err  error
+}
+
+// spinnerTickMsg advances the loading spinner
+type spinnerTickMsg struct{}
+
+// loadTableCmd fetches metadata, indices and the first page of a table
+func loadTableCmd(ctx context.Context, load int, dbm *db.Manager, table string, filter model.RowFilter, sorts []model.SortColumn) tea.Cmd {
+	return func() tea.Msg {
+		msg := tableLoadedMsg{table: table, load: load}
+
+		msg.metadata, msg.err = dbm.GetTableMetadata(ctx, table)
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			return msg
 		}
-		return m, nil
 
-	// Switch focus between left and right boxes
-	case "1":
-		m.focusLeft = true
-		// Reset editing state when switching focus
-		if m.editing {
-			m.editing = false
-			m.editBuffer = ""
+		msg.indices, msg.err = dbm.GetTableIndices(ctx, table)
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			return msg
 		}
-		return m, nil
 
-	case "2":
-		m.focusLeft = false
-		return m, nil
+		msg.foreignKeys, msg.err = dbm.GetForeignKeys(ctx, table)
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			return msg
+		}
 
-	case "?":
-		// Toggle help display
-		m.showEditHelp = !m.showEditHelp
-		return m, nil
-	}
+		msg.data, msg.err = dbm.GetTableData(ctx, table, filter, sorts, 0, pageSize)
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			return msg
+		}
 
-	// Handle edit mode keys
-	if m.editing {
-		return m.handleEditModeKeys(msg)
+		// The estimate is informational only, so a failure is not fatal
+		msg.estimate, _ = estimateRows(ctx, dbm, table, filter)
+		return msg
 	}
+}
 
-	// Handle normal mode keys
-	if m.focusLeft {
-		return m.handleLeftPanelKeys(msg)
-	} else {
-		return m.handleRightPanelKeys(msg)
+// loadPageCmd fetches the page of a table starting at offset, or the last page
+// when offset is lastPage
+func loadPageCmd(ctx context.Context, load int, dbm *db.Manager, table string, filter model.RowFilter, sorts []model.SortColumn, offset int, placement cursorPlacement) tea.Cmd {
+	return func() tea.Msg {
+		msg := pageLoadedMsg{table: table, load: load, offset: offset, placement: placement}
+
+		if offset == lastPage {
+			// Jumping to the end needs the exact row count to find the last page
+			count, err := dbm.CountRows(ctx, table, filter)
+			if err != nil {
+				msg.err = contextError(ctx, err)
+				return msg
+			}
+			msg.offset = 0
+			if count > 0 {
+				msg.offset = int((count-1)/pageSize) * pageSize
+			}
+		}
+
+		msg.data, msg.err = dbm.GetTableData(ctx, table, filter, sorts, msg.offset, pageSize)
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			return msg
+		}
+
+		msg.estimate, _ = estimateRows(ctx, dbm, table, filter)
+		return msg
 	}
 }
 
-// Handle keys when in filter mode
-func (m *AppModel) handleFilterModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		// Cancel filtering completely - remove filter and exit filter mode
-		m.filtering = false
-		m.filterBuffer = ""
-		m.filteredTables = nil
-		m.matchPositions = make(map[string][]int) // Reset match positions
-		return m, nil
+// estimateRows returns the server's row estimate for a table, or the exact count of
+// the matching rows when a filter makes the estimate meaningless
+func estimateRows(ctx context.Context, dbm *db.Manager, table string, filter model.RowFilter) (int64, error) {
+	if filter.IsEmpty() {
+		return dbm.EstimateRowCount(ctx, table)
+	}
+	return dbm.CountRows(ctx, table, filter)
+}
 
-	case "enter":
-		// Apply the filter and exit filter mode, but keep the filter results
-		m.applyFilter()
-		m.filtering = false // Exit filtering mode while keeping filteredTables
-		return m, nil
+// loadDDLCmd fetches the create statement of a table
+func loadDDLCmd(ctx context.Context, load int, dbm *db.Manager, table string) tea.Cmd {
+	return func() tea.Msg {
+		ddl, err := dbm.GetCreateStatement(ctx, table)
+		if err != nil {
+			err = contextError(ctx, err)
+		}
+		return ddlLoadedMsg{table: table, load: load, ddl: ddl, err: err}
+	}
+}
 
-	case "backspace":
-		// Delete last character and update filter in real-time
-		if len(m.filterBuffer) > 0 {
-			m.filterBuffer = m.filterBuffer[:len(m.filterBuffer)-1]
-			m.applyFilter()
+// runQueryCmd executes a statement from the query editor
+func runQueryCmd(ctx context.Context, generation int, dbm *db.Manager, query string) tea.Cmd {
+	return func() tea.Msg {
+		result, err := dbm.Query(ctx, query)
+		if err != nil {
+			err = contextError(ctx, err)
 		}
-		return m, nil
+		return queryResultMsg{generation: generation, result: result, err: err}
+	}
+}
 
-	default:
-		// Only add printable characters (ASCII 32-126) to the filter buffer
-		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
-			m.filterBuffer += msg.String()
-			m.applyFilter()
+// commitChangesCmd writes staged changes in a single transaction
+func commitChangesCmd(ctx context.Context, dbm *db.Manager, changes []model.Change) tea.Cmd {
+	return func() tea.Msg {
+		affected, err := dbm.ApplyChanges(ctx, changes)
+		if err != nil {
+			err = contextError(ctx, err)
 		}
-		return m, nil
+		return changesCommittedMsg{changes: changes, affected: affected, err: err}
 	}
 }
 
-// Apply the current filter to the tables list
-func (m *AppModel) applyFilter() {
-	// Reset match positions
-	m.matchPositions = make(map[string][]int)
-	
-	if m.filterBuffer == "" {
-		// If filter is empty, show all tables
-		m.filteredTables = nil
-		return
+// listDatabasesCmd fetches the databases the switcher offers
+func listDatabasesCmd(ctx context.Context, dbm *db.Manager) tea.Cmd {
+	return func() tea.Msg {
+		databases, err := dbm.GetDatabaseNames(ctx)
+		if err != nil {
+			err = contextError(ctx, err)
+		}
+		return databasesListedMsg{databases: databases, err: err}
 	}
+}
 
-	// Filter the tables list based on fuzzy matching
-	type matchResult struct {
-		table      string
-		score      int
-		positions  []int
+// switchDatabaseCmd connects to another database and lists its tables
+func switchDatabaseCmd(ctx context.Context, dbm *db.Manager, name string) tea.Cmd {
+	return func() tea.Msg {
+		msg := databaseSwitchedMsg{name: name}
+
+		next, err := dbm.SwitchDatabase(ctx, name)
+		if err != nil {
+			msg.err = contextError(ctx, err)
+			return msg
+		}
+
+		msg.tables, msg.err = next.GetTables(ctx)
+		if msg.err == nil {
+			msg.objects, msg.err = next.GetObjects(ctx)
+		}
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
+			next.Close()
+			return msg
+		}
+
+		msg.manager = next
+		return msg
 	}
-	
-	var matches []matchResult
-	
-	// Find all tables that match the filter and their scores
-	for _, table := range m.tables {
-		score, positions := fuzzyMatch(m.filterBuffer, table)
-		if score > 0 { // 0 means no match now
-			matches = append(matches, matchResult{
-				table:     table,
-				score:     score,
-				positions: positions,
-			})
+}
+
+// exportTableCmd streams the rows of a table matching the filter, in the sort order,
+// to a file
+func exportTableCmd(ctx context.Context, dbm *db.Manager, table string, filter model.RowFilter, sorts []model.SortColumn, path string, format db.ExportFormat) tea.Cmd {
+	return func() tea.Msg {
+		msg := exportDoneMsg{path: path}
+		msg.err = writeExportFile(path, func(w io.Writer) error {
+			var err error
+			msg.rows, err = dbm.ExportTable(ctx, w, format, table, filter, sorts)
+			return err
+		})
+		if msg.err != nil {
+			msg.err = contextError(ctx, msg.err)
 		}
+		return msg
 	}
-	
-	// Sort matches by score (higher is better)
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].score > matches[j].score
-	})
-	
-	// Extract sorted table names and store match positions
-	m.filteredTables = make([]string, len(matches))
-	for i, match := range matches {
-		m.filteredTables[i] = match.table
-		// Store positions for highlighting
-		m.matchPositions[match.table] = match.positions
+}
+
+// exportResultCmd writes the rows of a query result to a file
+func exportResultCmd(result *model.QueryResult, path string, format db.ExportFormat) tea.Cmd {
+	return func() tea.Msg {
+		err := writeExportFile(path, func(w io.Writer) error {
+			return db.ExportResult(w, format, result)
+		})
+		return exportDoneMsg{path: path, rows: int64(len(result.Rows)), err: err}
 	}
+}
 
-	// Reset selection if needed
-	if len(m.filteredTables) > 0 {
-		if m.selectedIdx >= len(m.filteredTables) {
-			m.selectedIdx = len(m.filteredTables) - 1
-		}
-	} else {
-		m.selectedIdx = 0
+// writeExportFile creates the file at path, which must not exist yet, and fills it
+// with write, removing it again if the export fails half way
+func writeExportFile(path string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("error creating export file: %v", err)
+	}
+
+	err = write(f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing export file: %v", closeErr)
 	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
 }
 
-// fuzzyMatch provides a better fuzzy matching algorithm
-// Returns a score (higher is better, 0 means no match) and match positions
-func fuzzyMatch(pattern, str string) (int, []int) {
-    // Convert to lowercase for case-insensitive matching
-    patternLower := strings.ToLower(pattern)
-    strLower := strings.ToLower(str)
-    
-    // Empty pattern matches everything with score 0
-    if len(patternLower) == 0 {
-        return 0, nil
-    }
-    
-    // If the pattern is longer than the string, it can't match
-    if len(patternLower) > len(strLower) {
+// contextError reports the context's own error when it ended the operation, since
+// drivers surface cancellation in many different ways
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// spinnerTick schedules the next spinner frame
+func spinnerTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// Tea model interface implementation
+func (m AppModel) Init() tea.Cmd {
+	return m.startupCmd
+}
+
+func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = ui.NewStyles(m.width, m.height)
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKeyPress(msg)
+
+	case tableLoadedMsg:
+		if !m.finishLoading(msg.table, msg.load) {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Loading %s failed: %s", msg.table, m.describeError(msg.err))
+			return m, nil
+		}
+
+		m.tableMetadata[msg.table] = msg.metadata
+		m.tableIndices[msg.table] = msg.indices
+		m.tableForeignKeys[msg.table] = msg.foreignKeys
+		m.storePage(msg.table, 0, msg.data, msg.estimate)
+
+		// The DDL view loads its statement once the table itself is in
+		if m.mode == model.DDLMode && m.activeTableIdx < len(m.tables) && m.tables[m.activeTableIdx] == msg.table {
+			return m, m.loadDDL(msg.table)
+		}
+		return m, nil
+
+	case ddlLoadedMsg:
+		if !m.finishLoading(msg.table, msg.load) {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Loading DDL of %s failed: %s", msg.table, m.describeError(msg.err))
+			return m, nil
+		}
+
+		m.tableDDL[msg.table] = msg.ddl
+		return m, nil
+
+	case pageLoadedMsg:
+		if !m.finishLoading(msg.table, msg.load) {
+			return m, nil
+		}
+		previous, filterChanged := m.rowFilterRestore[msg.table]
+		delete(m.rowFilterRestore, msg.table)
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Loading rows failed: %s", m.describeError(msg.err))
+			if filterChanged {
+				// Keep showing the rows of the filter that worked
+				m.tableFilter[msg.table] = previous
+				m.statusMessage = fmt.Sprintf("Filter failed: %s", m.describeError(msg.err))
+			}
+			return m, nil
+		}
+
+		m.storePage(msg.table, msg.offset, msg.data, msg.estimate)
+		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) && m.tables[m.activeTableIdx] == msg.table {
+			m.placeCursor(msg.placement)
+
+			// A DDL view opened while the page was loading fetches its statement now
+			if m.mode == model.DDLMode {
+				return m, m.loadDDL(msg.table)
+			}
+		}
+		return m, nil
+
+	case queryResultMsg:
+		if msg.generation != m.generation {
+			// Ran against the database that was switched away from
+			return m, nil
+		}
+		m.queryRunning = false
+		if m.queryCancel != nil {
+			m.queryCancel()
+			m.queryCancel = nil
+		}
+		if msg.err != nil {
+			m.queryStatus = fmt.Sprintf("Error: %s", m.describeError(msg.err))
+			return m, nil
+		}
+
+		m.queryResult = msg.result
+		m.cursorRow = 0
+		m.cursorCol = 0
+		m.mainScroll = 0
+
+		elapsed := msg.result.Elapsed.Round(time.Millisecond)
+		if msg.result.IsQuery {
+			m.queryStatus = fmt.Sprintf("%d row(s) in %s", len(msg.result.Rows), elapsed)
+		} else {
+			m.queryStatus = fmt.Sprintf("Query OK, %d row(s) affected in %s", msg.result.RowsAffected, elapsed)
+		}
+		return m, nil
+
+	case changesCommittedMsg:
+		return m, m.finishCommit(msg)
+
+	case databasesListedMsg:
+		if !m.listingDatabases {
+			return m, nil
+		}
+		m.listingDatabases = false
+		if m.databaseCancel != nil {
+			m.databaseCancel()
+			m.databaseCancel = nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Listing databases failed: %s", m.describeError(msg.err))
+			return m, nil
+		}
+		m.showDatabasePickerFor(msg.databases)
+		return m, nil
+
+	case databaseSwitchedMsg:
+		m.switchingDatabase = false
+		if m.databaseCancel != nil {
+			m.databaseCancel()
+			m.databaseCancel = nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Switching to %s failed: %s", msg.name, m.describeError(msg.err))
+			return m, nil
+		}
+		return m, m.adoptDatabase(msg.manager, msg.tables, msg.objects)
+
+	case exportDoneMsg:
+		m.exporting = false
+		if m.exportCancel != nil {
+			m.exportCancel()
+			m.exportCancel = nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Export to %s failed: %s", msg.path, m.describeError(msg.err))
+			return m, nil
+		}
+
+		m.statusMessage = fmt.Sprintf("Exported %d row(s) to %s", msg.rows, msg.path)
+		return m, nil
+
+	case spinnerTickMsg:
+		// Keep ticking only while something is loading
+		if !m.busy() {
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, spinnerTick()
+	}
+
+	return m, nil
+}
+
+// activateTable makes a table the active one, loading it in the background on first use
+func (m *AppModel) activateTable(idx int) tea.Cmd {
+	m.activeTableIdx = idx
+	m.activeObject = nil
+
+	// Reset main content scroll when changing tables
+	m.mainScroll = 0
+	m.cursorRow = 0
+	m.cursorCol = 0
+	m.relationIdx = 0
+
+	table := m.tables[idx]
+	if _, loaded := m.tableMetadata[table]; loaded || m.tableLoading[table] {
+		if loaded && m.mode == model.DDLMode {
+			return m.loadDDL(table)
+		}
+		return nil
+	}
+
+	filter, sorts := m.tableFilter[table], m.tableSort[table]
+	return m.startLoading(table, func(ctx context.Context, load int) tea.Cmd {
+		return loadTableCmd(ctx, load, m.dbManager, table, filter, sorts)
+	})
+}
+
+// loadDDL fetches the create statement of a table unless it is cached or the table
+// is busy loading; in the latter case it is fetched when the table load finishes
+func (m *AppModel) loadDDL(table string) tea.Cmd {
+	if _, ok := m.tableDDL[table]; ok || m.tableLoading[table] {
+		return nil
+	}
+	return m.startLoading(table, func(ctx context.Context, load int) tea.Cmd {
+		return loadDDLCmd(ctx, load, m.dbManager, table)
+	})
+}
+
+// startLoading marks a table as loading and runs the command built by makeCmd under
+// a cancellable context bounded by the query timeout. The command is given a load
+// number to report back with; a load still in flight for the table is cancelled and
+// its result ignored. The spinner starts if it is idle.
+func (m *AppModel) startLoading(table string, makeCmd func(ctx context.Context, load int) tea.Cmd) tea.Cmd {
+	if cancel, ok := m.loadCancels[table]; ok {
+		cancel()
+	}
+
+	idle := !m.busy()
+	ctx, cancel := m.newContext()
+	m.loadSeq++
+	m.tableLoading[table] = true
+	m.loadCancels[table] = cancel
+	m.loadIDs[table] = m.loadSeq
+
+	cmd := makeCmd(ctx, m.loadSeq)
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// finishLoading clears the loading state of a table once its load reports back. It
+// returns false for a load that has since been replaced, whose result is stale.
+func (m *AppModel) finishLoading(table string, load int) bool {
+	if m.loadIDs[table] != load {
+		return false
+	}
+
+	if cancel, ok := m.loadCancels[table]; ok {
+		cancel()
+		delete(m.loadCancels, table)
+	}
+	delete(m.tableLoading, table)
+	delete(m.loadIDs, table)
+	return true
+}
+
+// newContext returns a context bounded by the configured query timeout
+func (m *AppModel) newContext() (context.Context, context.CancelFunc) {
+	return db.WithTimeout(context.Background(), m.dbConfig.QueryTimeout)
+}
+
+// cancelRunning aborts all background database work; each command then reports
+// back with a cancellation error
+func (m *AppModel) cancelRunning() {
+	for _, cancel := range m.loadCancels {
+		cancel()
+	}
+	if m.queryCancel != nil {
+		m.queryCancel()
+	}
+	if m.commitCancel != nil {
+		m.commitCancel()
+	}
+	if m.databaseCancel != nil {
+		m.databaseCancel()
+	}
+	if m.exportCancel != nil {
+		m.exportCancel()
+	}
+	m.statusMessage = "Cancelling..."
+}
+
+// describeError turns cancellation and timeout errors into readable messages
+func (m *AppModel) describeError(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("timed out after %s", m.dbConfig.QueryTimeout)
+	}
+	return err.Error()
+}
+
+// busy reports whether any background database work is in flight
+func (m *AppModel) busy() bool {
+	return len(m.tableLoading) > 0 || m.queryRunning || m.listingDatabases || m.switchingDatabase || m.committing || m.exporting
+}
+
+// openDatabasePicker starts listing the databases on the server; the switcher is
+// shown once they arrive
+func (m *AppModel) openDatabasePicker() tea.Cmd {
+	if count := m.pendingChangeCount(); count > 0 {
+		m.statusMessage = "Commit or discard pending changes before switching databases"
+		return nil
+	}
+	if m.listingDatabases || m.switchingDatabase {
+		return nil
+	}
+
+	ctx, cancel := m.newContext()
+	cmd := listDatabasesCmd(ctx, m.dbManager)
+
+	idle := !m.busy()
+	m.listingDatabases = true
+	m.databaseCancel = cancel
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// showDatabasePickerFor shows the switcher with the current database selected
+func (m *AppModel) showDatabasePickerFor(databases []string) {
+	m.databases = databases
+	m.databaseIdx = 0
+	current := m.dbManager.CurrentDatabase()
+	for i, name := range databases {
+		if name == current {
+			m.databaseIdx = i
+			break
+		}
+	}
+	m.showDatabasePicker = true
+}
+
+// switchDatabase starts connecting to the named database in the background
+func (m *AppModel) switchDatabase(name string) tea.Cmd {
+	if name == m.dbManager.CurrentDatabase() || m.switchingDatabase {
+		return nil
+	}
+
+	m.cancelRunning()
+	m.statusMessage = fmt.Sprintf("Switching to %s...", name)
+
+	ctx, cancel := m.newContext()
+	cmd := switchDatabaseCmd(ctx, m.dbManager, name)
+
+	idle := !m.busy()
+	m.switchingDatabase = true
+	m.databaseCancel = cancel
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// adoptDatabase replaces the current connection with one to another database and
+// resets all per-table state before loading the first table
+func (m *AppModel) adoptDatabase(dbm *db.Manager, tables []model.TableInfo, objects []model.SchemaObject) tea.Cmd {
+	m.dbManager.Close()
+	m.dbManager = dbm
+	m.dbConfig = dbm.Config()
+
+	// Work still running against the old database was cancelled by switchDatabase.
+	// Its query result is told apart by the generation, and its table loads by their
+	// load numbers, which keep counting across switches.
+	m.generation++
+	if m.queryCancel != nil {
+		m.queryCancel()
+		m.queryCancel = nil
+	}
+	m.queryRunning = false
+	m.queryResult = nil
+	m.queryStatus = ""
+
+	m.setTables(tables)
+	m.objects = objects
+	m.activeObject = nil
+	m.tableData = make(map[string][]model.RowData)
+	m.tableMetadata = make(map[string][]model.ColumnMetadata)
+	m.tableIndices = make(map[string][]model.IndexMetadata)
+	m.tableForeignKeys = make(map[string][]model.ForeignKey)
+	m.tableDDL = make(map[string]string)
+	m.tableOffset = make(map[string]int)
+	m.tableHasMore = make(map[string]bool)
+	m.tableRowEstimate = make(map[string]int64)
+	m.tableLoading = make(map[string]bool)
+	m.tableFilter = make(map[string]model.RowFilter)
+	m.tableSort = make(map[string][]model.SortColumn)
+	m.rowFilterRestore = make(map[string]model.RowFilter)
+	m.rowFilterPrompt = ""
+	m.navHistory = nil
+	m.loadCancels = make(map[string]context.CancelFunc)
+	m.loadIDs = make(map[string]int)
+	m.pendingChanges = make(map[string][]model.Change)
+
+	m.filtering = false
+	m.filterBuffer = ""
+	m.filteredTables = nil
+	m.filteredObjects = nil
+	m.matchPositions = make(map[string][]int)
+
+	m.activeTableIdx = 0
+	m.sidebarScroll = 0
+	m.selectSidebarRow(0, 1)
+	m.editing = false
+	m.editBuffer = ""
+
+	m.statusMessage = fmt.Sprintf("Switched to %s", m.dbManager.CurrentDatabase())
+	if len(tables) == 0 {
+		m.mainScroll = 0
+		m.cursorRow = 0
+		m.cursorCol = 0
+		return nil
+	}
+	return m.activateTable(0)
+}
+
+// Handle keys while the database switcher is open
+func (m *AppModel) handleDatabasePickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showDatabasePicker = false
+	case "up", "k":
+		if m.databaseIdx > 0 {
+			m.databaseIdx--
+		}
+	case "down", "j":
+		if m.databaseIdx < len(m.databases)-1 {
+			m.databaseIdx++
+		}
+	case "enter":
+		m.showDatabasePicker = false
+		if m.databaseIdx < len(m.databases) {
+			return m, m.switchDatabase(m.databases[m.databaseIdx])
+		}
+	}
+	return m, nil
+}
+
+// runQuery executes the query editor buffer in the background
+func (m *AppModel) runQuery() tea.Cmd {
+	query := strings.TrimSpace(m.queryBuffer)
+	if query == "" || m.queryRunning {
+		return nil
+	}
+	ctx, cancel := m.newContext()
+	cmd := runQueryCmd(ctx, m.generation, m.dbManager, query)
+
+	idle := !m.busy()
+	m.queryRunning = true
+	m.queryCancel = cancel
+	m.queryStatus = ""
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// Handle keys while typing in the query editor
+func (m *AppModel) handleQueryEditorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Leave the editor to navigate the results
+		m.queryEditing = false
+		return m, nil
+	case "ctrl+r", "f5":
+		return m, m.runQuery()
+	case "enter":
+		m.queryBuffer += "\n"
+		return m, nil
+	case "tab":
+		m.queryBuffer += "  "
+		return m, nil
+	case "backspace":
+		if len(m.queryBuffer) > 0 {
+			runes := []rune(m.queryBuffer)
+			m.queryBuffer = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		m.queryBuffer += string(msg.Runes)
+	case tea.KeySpace:
+		m.queryBuffer += " "
+	}
+	return m, nil
+}
+
+// Handle keys in the Query view while navigating the result grid
+func (m *AppModel) handleQueryResultKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "e":
+		m.queryEditing = true
+		return m, nil
+	case "ctrl+r", "f5":
+		return m, m.runQuery()
+	}
+
+	if m.queryResult == nil {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "E":
+		m.openExportPrompt()
+	case "up", "k":
+		if m.cursorRow > 0 {
+			m.cursorRow--
+			if m.cursorRow < m.mainScroll {
+				m.mainScroll = m.cursorRow
+			}
+		}
+	case "down", "j":
+		if m.cursorRow < len(m.queryResult.Rows)-1 {
+			m.cursorRow++
+
+			// The editor takes part of the box above the result grid
+			visibleHeight := m.styles.MainBoxStyle.GetHeight() - ui.QueryEditorHeight - 3
+			if m.cursorRow >= m.mainScroll+visibleHeight {
+				m.mainScroll = m.cursorRow - visibleHeight + 1
+			}
+		}
+	case "left", "h":
+		if m.cursorCol > 0 {
+			m.cursorCol--
+		}
+	case "right", "l":
+		if m.cursorCol < len(m.queryResult.Columns)-1 {
+			m.cursorCol++
+		}
+	}
+	return m, nil
+}
+
+// Handle key presses based on current state
+func (m *AppModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Status messages only live until the next key press
+	m.statusMessage = ""
+	if msg.String() != "q" && msg.String() != "ctrl+c" {
+		m.confirmQuit = false
+	}
+
+	// Handle modal input first if it's active
+	if m.showEditModal {
+		return m.handleEditModalKeys(msg)
+	}
+
+	if m.showReview {
+		return m.handleReviewKeys(msg)
+	}
+
+	if m.confirmDelete {
+		return m.handleDeleteConfirmKeys(msg)
+	}
+
+	if m.showDatabasePicker {
+		return m.handleDatabasePickerKeys(msg)
+	}
+
+	if m.rowFilterPrompt != "" {
+		return m.handleRowFilterPromptKeys(msg)
+	}
+
+	if m.dataSearching {
+		return m.handleDataSearchKeys(msg)
+	}
+
+	if m.exportPrompt {
+		return m.handleExportPromptKeys(msg)
+	}
+
+	// Esc aborts running database work before it does anything else
+	if msg.String() == "esc" && m.busy() && !m.editing {
+		m.cancelRunning()
+		return m, nil
+	}
+
+	// The query editor captures all typing, including keys that are global elsewhere
+	if m.queryEditing && !m.focusLeft && m.mode == model.QueryMode && msg.String() != "ctrl+c" {
+		return m.handleQueryEditorKeys(msg)
+	}
+
+	// Handle filtering mode if it's active
+	if m.filtering && m.focusLeft {
+		return m.handleFilterModeKeys(msg)
+	}
+
+	// Handle global keys first
+	switch msg.String() {
+	case "ctrl+c", "q":
+		// Require a second press when there are uncommitted changes
+		if count := m.pendingChangeCount(); count > 0 && !m.confirmQuit {
+			m.confirmQuit = true
+			m.statusMessage = fmt.Sprintf("%d uncommitted change(s) will be lost, press q again to quit", count)
+			return m, nil
+		}
+		return m, tea.Quit // Exit app
+
+	case "tab":
+		// Toggle focus between left and right boxes
+		m.focusLeft = !m.focusLeft
+		// Reset editing state when switching focus
+		if m.focusLeft && m.editing {
+			m.editing = false
+			m.editBuffer = ""
+		}
+		return m, nil
+
+	// Switch focus between left and right boxes
+	case "1":
+		m.focusLeft = true
+		// Reset editing state when switching focus
+		if m.editing {
+			m.editing = false
+			m.editBuffer = ""
+		}
+		return m, nil
+
+	case "2":
+		m.focusLeft = false
+		return m, nil
+
+	case "?":
+		// Toggle help display
+		m.showEditHelp = !m.showEditHelp
+		return m, nil
+
+	case "D":
+		if !m.editing {
+			return m, m.openDatabasePicker()
+		}
+	}
+
+	// Handle edit mode keys
+	if m.editing {
+		return m.handleEditModeKeys(msg)
+	}
+
+	// Handle normal mode keys
+	if m.focusLeft {
+		return m.handleLeftPanelKeys(msg)
+	} else {
+		return m.handleRightPanelKeys(msg)
+	}
+}
+
+// Handle keys when in filter mode
+func (m *AppModel) handleFilterModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Cancel filtering completely - remove filter and exit filter mode
+		m.filtering = false
+		m.filterBuffer = ""
+		m.filteredTables = nil
+		m.filteredObjects = nil
+		m.matchPositions = make(map[string][]int) // Reset match positions
+		m.selectSidebarRow(m.selectedIdx, 1)
+		return m, nil
+
+	case "enter":
+		// Apply the filter and exit filter mode, but keep the filter results
+		m.applyFilter()
+		m.filtering = false // Exit filtering mode while keeping filteredTables
+		return m, nil
+
+	case "backspace":
+		// Delete last character and update filter in real-time
+		if len(m.filterBuffer) > 0 {
+			m.filterBuffer = m.filterBuffer[:len(m.filterBuffer)-1]
+			m.applyFilter()
+		}
+		return m, nil
+
+	default:
+		// Only add printable characters (ASCII 32-126) to the filter buffer
+		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
+			m.filterBuffer += msg.String()
+			m.applyFilter()
+		}
+		return m, nil
+	}
+}
+
+// Apply the current filter to the tables list
+func (m *AppModel) applyFilter() {
+	// Reset match positions
+	m.matchPositions = make(map[string][]int)
+	
+	if m.filterBuffer == "" {
+		// If filter is empty, show all tables
+		m.filteredTables = nil
+		m.filteredObjects = nil
+		m.selectSidebarRow(m.selectedIdx, 1)
+		return
+	}
+
+	// Filter the tables list based on fuzzy matching
+	type matchResult struct {
+		table      string
+		score      int
+		positions  []int
+	}
+	
+	var matches []matchResult
+	
+	// Find all tables that match the filter and their scores
+	for _, table := range m.tables {
+		score, positions := fuzzyMatch(m.filterBuffer, table)
+		if score > 0 { // 0 means no match now
+			matches = append(matches, matchResult{
+				table:     table,
+				score:     score,
+				positions: positions,
+			})
+		}
+	}
+	
+	// Sort matches by score (higher is better)
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	
+	// Extract sorted table names and store match positions
+	m.filteredTables = make([]string, len(matches))
+	for i, match := range matches {
+		m.filteredTables[i] = match.table
+		// Store positions for highlighting
+		m.matchPositions[match.table] = match.positions
+	}
+
+	// Other objects are filtered the same way; the sidebar groups them by kind
+	type objectMatch struct {
+		object *model.SchemaObject
+		score  int
+	}
+	var objectMatches []objectMatch
+	for i := range m.objects {
+		if score, _ := fuzzyMatch(m.filterBuffer, m.objects[i].Name); score > 0 {
+			objectMatches = append(objectMatches, objectMatch{object: &m.objects[i], score: score})
+		}
+	}
+	sort.SliceStable(objectMatches, func(i, j int) bool {
+		return objectMatches[i].score > objectMatches[j].score
+	})
+	m.filteredObjects = make([]*model.SchemaObject, len(objectMatches))
+	for i, match := range objectMatches {
+		m.filteredObjects[i] = match.object
+	}
+
+	// Reset selection if needed
+	m.selectSidebarRow(m.selectedIdx, 1)
+}
+
+// fuzzyMatch provides a better fuzzy matching algorithm
+// Returns a score (higher is better, 0 means no match) and match positions
+func fuzzyMatch(pattern, str string) (int, []int) {
+    // Convert to lowercase for case-insensitive matching
+    patternLower := strings.ToLower(pattern)
+    strLower := strings.ToLower(str)
+    
+    // Empty pattern matches everything with score 0
+    if len(patternLower) == 0 {
+        return 0, nil
+    }
+    
+    // If the pattern is longer than the string, it can't match
+    if len(patternLower) > len(strLower) {
         return 0, nil
     }
     
@@ -507,320 +1467,1263 @@ func fuzzyMatch(pattern, str string) (int, []int) {
     return 0, nil
 }
 
-// Helper method to get either filtered tables or all tables
-func (m *AppModel) getFilteredOrAllTables() []string {
-	if m.filteredTables != nil {
-		return m.filteredTables
+// sidebarRow is one row of the sidebar tree: a group header, a table or view, or
+// another schema object
+type sidebarRow struct {
+	header string
+	table  string
+	object *model.SchemaObject
+}
+
+// sidebarRows lays out the sidebar tree from the filtered, or all, tables and
+// objects, grouped by kind with a header above each non-empty group
+func (m *AppModel) sidebarRows() []sidebarRow {
+	tables := m.tables
+	objects := make([]*model.SchemaObject, len(m.objects))
+	for i := range m.objects {
+		objects[i] = &m.objects[i]
+	}
+	if m.filteredTables != nil {
+		tables, objects = m.filteredTables, m.filteredObjects
+	}
+
+	var rows []sidebarRow
+	for _, kind := range model.ObjectKinds {
+		var group []sidebarRow
+		for _, table := range tables {
+			if m.tableTypes[table].Kind() == kind {
+				group = append(group, sidebarRow{table: table})
+			}
+		}
+		for _, obj := range objects {
+			if obj.Kind == kind {
+				group = append(group, sidebarRow{object: obj})
+			}
+		}
+
+		if len(group) > 0 {
+			rows = append(rows, sidebarRow{header: fmt.Sprintf("%s (%d)", kind.Group(), len(group))})
+			rows = append(rows, group...)
+		}
+	}
+	return rows
+}
+
+// selectSidebarRow selects sidebar row idx, stepping over group headers in direction
+// dir (or back the other way at either end of the list), and scrolls the sidebar so
+// the selection and its group header stay in view
+func (m *AppModel) selectSidebarRow(idx, dir int) {
+	rows := m.sidebarRows()
+	idx = max(0, min(idx, len(rows)-1))
+
+	found := -1
+	for _, step := range []int{dir, -dir} {
+		for i := idx; found < 0 && i >= 0 && i < len(rows); i += step {
+			if rows[i].header == "" {
+				found = i
+			}
+		}
+	}
+	if found < 0 {
+		m.selectedIdx = 0
+		return
+	}
+	m.selectedIdx = found
+
+	top := found
+	if top > 0 && rows[top-1].header != "" {
+		top--
+	}
+	visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
+	if top < m.sidebarScroll {
+		m.sidebarScroll = top
+	} else if visibleHeight > 0 && found >= m.sidebarScroll+visibleHeight {
+		m.sidebarScroll = found - visibleHeight + 1
+	}
+}
+
+// activateObject shows a procedure, function, trigger or event in the right panel
+func (m *AppModel) activateObject(obj *model.SchemaObject) {
+	m.activeObject = obj
+	m.mainScroll = 0
+}
+
+// Handle keys when in edit mode
+func (m *AppModel) handleEditModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Cancel edit
+		m.editing = false
+		m.editBuffer = ""
+		return m, nil
+
+	case "enter":
+		// Submit edit; invalid input stays in the editor to be fixed
+		if !m.applyEdit() {
+			return m, nil
+		}
+		m.editing = false
+		m.editBuffer = ""
+		return m, nil
+
+	case "backspace":
+		// Delete last character
+		if len(m.editBuffer) > 0 {
+			m.editBuffer = m.editBuffer[:len(m.editBuffer)-1]
+		}
+		return m, nil
+
+	default:
+		// Only add printable characters (ASCII 32-126) to the edit buffer
+		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
+			m.editBuffer += msg.String()
+		}
+		return m, nil
+	}
+}
+
+// Handle keys when focus is on the left panel (object tree)
+func (m *AppModel) handleLeftPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Clear filter if one is applied
+		if m.filteredTables != nil {
+			m.filterBuffer = ""
+			m.filteredTables = nil
+			m.filteredObjects = nil
+			m.selectSidebarRow(0, 1) // Reset selection to the first item
+		}
+		return m, nil
+	case "/":
+		// Activate filter mode
+		m.filtering = true
+		m.filterBuffer = ""
+		m.filteredTables = nil
+		m.filteredObjects = nil
+		return m, nil
+	case "up", "k":
+		m.selectSidebarRow(m.selectedIdx-1, -1)
+		return m, nil
+	case "down", "j":
+		m.selectSidebarRow(m.selectedIdx+1, 1)
+		return m, nil
+	case "enter":
+		// Activate the selected table or object
+		rows := m.sidebarRows()
+		if m.selectedIdx >= len(rows) {
+			return m, nil
+		}
+		row := rows[m.selectedIdx]
+		if row.object != nil {
+			m.activateObject(row.object)
+			return m, nil
+		}
+		// Find the real index in the full tables list
+		for i, table := range m.tables {
+			if table == row.table {
+				return m, m.activateTable(i)
+			}
+		}
+		return m, nil
+	case "pgup":
+		// Page up - scroll up by visible height
+		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
+		m.sidebarScroll -= visibleHeight
+		if m.sidebarScroll < 0 {
+			m.sidebarScroll = 0
+		}
+
+		// Also move selection if it's now out of view
+		if m.selectedIdx >= m.sidebarScroll+visibleHeight {
+			m.selectedIdx = m.sidebarScroll + visibleHeight - 1
+		} else if m.selectedIdx < m.sidebarScroll {
+			m.selectedIdx = m.sidebarScroll
+		}
+		m.selectSidebarRow(m.selectedIdx, -1)
+		return m, nil
+	case "pgdown":
+		// Page down - scroll down by visible height
+		rows := m.sidebarRows()
+		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
+		maxScroll := len(rows) - visibleHeight
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+
+		m.sidebarScroll += visibleHeight
+		if m.sidebarScroll > maxScroll {
+			m.sidebarScroll = maxScroll
+		}
+
+		// Also move selection if it's now out of view
+		if m.selectedIdx < m.sidebarScroll {
+			m.selectedIdx = m.sidebarScroll
+		} else if m.selectedIdx >= m.sidebarScroll+visibleHeight {
+			m.selectedIdx = m.sidebarScroll + visibleHeight - 1
+			if m.selectedIdx >= len(rows) {
+				m.selectedIdx = len(rows) - 1
+			}
+		}
+		m.selectSidebarRow(m.selectedIdx, 1)
+		return m, nil
+	case "home":
+		// Scroll to top
+		m.sidebarScroll = 0
+		if m.selectedIdx < m.sidebarScroll {
+			m.selectedIdx = m.sidebarScroll
+		}
+		return m, nil
+	case "end":
+		// Scroll to bottom
+		rows := m.sidebarRows()
+		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
+		maxScroll := len(rows) - visibleHeight
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+		m.sidebarScroll = maxScroll
+
+		if m.selectedIdx < m.sidebarScroll {
+			m.selectSidebarRow(m.sidebarScroll, 1)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// Handle keys when focus is on the right panel (table data)
+func (m *AppModel) handleRightPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// An object shown from the sidebar stays in place of the table views until one
+	// of them is chosen again
+	if m.activeObject != nil && m.mode != model.QueryMode {
+		switch msg.String() {
+		case "d", "s", "i", "r", "c":
+			m.activeObject = nil
+		case "x", "b", "backspace":
+			// The query editor and back navigation work as usual
+		default:
+			return m.handleObjectKeys(msg)
+		}
+	}
+
+	// Tab switching
+	switch msg.String() {
+	case "d":
+		if m.mode == model.QueryMode {
+			// The cursor was pointing into the query result
+			m.cursorRow = 0
+			m.cursorCol = 0
+		}
+		m.mode = model.DataMode
+		m.mainScroll = 0 // Reset scroll position when changing view
+		return m, nil
+	case "x":
+		m.mode = model.QueryMode
+		m.queryEditing = true
+		m.mainScroll = 0 // Reset scroll position when changing view
+		m.cursorRow = 0
+		m.cursorCol = 0
+		return m, nil
+	case "s":
+		m.mode = model.StructureMode
+		m.mainScroll = 0 // Reset scroll position when changing view
+		return m, nil
+	case "i":
+		m.mode = model.IndicesMode
+		m.mainScroll = 0 // Reset scroll position when changing view
+		return m, nil
+	case "r":
+		m.mode = model.RelationsMode
+		m.mainScroll = 0 // Reset scroll position when changing view
+		m.relationIdx = 0
+		return m, nil
+	case "c":
+		m.mode = model.DDLMode
+		m.mainScroll = 0 // Reset scroll position when changing view
+		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+			return m, m.loadDDL(m.tables[m.activeTableIdx])
+		}
+		return m, nil
+	case "b", "backspace":
+		// Return to where the last foreign key or relation jump started
+		if m.mode != model.QueryMode {
+			return m, m.goBack()
+		}
+	}
+
+	// Data mode fetches further pages from the server when scrolling past the loaded rows
+	if m.mode == model.DataMode {
+		if handled, cmd := m.handleDataPaging(msg.String()); handled {
+			return m, cmd
+		}
+	}
+
+	// Common scrolling keys for all modes
+	switch msg.String() {
+	case "pgup":
+		// Page up - scroll up by visible height
+		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
+		m.mainScroll -= visibleHeight
+		if m.mainScroll < 0 {
+			m.mainScroll = 0
+		}
+		return m, nil
+	case "pgdown":
+		var maxContent int
+		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+			table := m.tables[m.activeTableIdx]
+			// Determine content height based on mode
+			if m.mode == model.DataMode && m.tableData[table] != nil {
+				maxContent = len(m.tableData[table]) + 1 // +1 for header row
+			} else if m.mode == model.StructureMode && m.tableMetadata[table] != nil {
+				maxContent = ui.StructureLines(m.tableMetadata[table]) + 10 // +10 for title, header, scroll indicators and pagination
+			} else if m.mode == model.IndicesMode && m.tableIndices[table] != nil {
+				maxContent = len(m.tableIndices[table]) + 2 // +2 for title and blank line
+			} else if m.mode == model.RelationsMode && m.tableForeignKeys[table] != nil {
+				_, outgoing, incoming := m.activeRelations()
+				maxContent = ui.RelationsLines(outgoing, incoming) + 5 // +5 for title and scroll indicators
+			} else if m.mode == model.DDLMode && m.tableDDL[table] != "" {
+				maxContent = len(ui.DDLLines(m.tableDDL[table])) + 4 // +4 for title and scroll indicators
+			}
+		}
+		if m.mode == model.QueryMode && m.queryResult != nil {
+			maxContent = len(m.queryResult.Rows) + ui.QueryEditorHeight + 1 // +1 for header row
+		}
+
+		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
+		m.mainScroll += visibleHeight
+
+		// Calculate max scroll position
+		maxScroll := maxContent - visibleHeight
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+
+		if m.mainScroll > maxScroll {
+			m.mainScroll = maxScroll
+		}
+		return m, nil
+	case "home":
+		m.mainScroll = 0
+		return m, nil
+	case "end":
+		var maxContent int
+		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+			table := m.tables[m.activeTableIdx]
+			// Determine content height based on mode
+			if m.mode == model.DataMode && m.tableData[table] != nil {
+				maxContent = len(m.tableData[table]) + 1 // +1 for header row
+			} else if m.mode == model.StructureMode && m.tableMetadata[table] != nil {
+				maxContent = ui.StructureLines(m.tableMetadata[table]) + 10 // +10 for title, header, scroll indicators and pagination
+			} else if m.mode == model.IndicesMode && m.tableIndices[table] != nil {
+				maxContent = len(m.tableIndices[table]) + 2 // +2 for title and blank line
+			} else if m.mode == model.RelationsMode && m.tableForeignKeys[table] != nil {
+				_, outgoing, incoming := m.activeRelations()
+				maxContent = ui.RelationsLines(outgoing, incoming) + 5 // +5 for title and scroll indicators
+			} else if m.mode == model.DDLMode && m.tableDDL[table] != "" {
+				maxContent = len(ui.DDLLines(m.tableDDL[table])) + 4 // +4 for title and scroll indicators
+			}
+		}
+		if m.mode == model.QueryMode && m.queryResult != nil {
+			maxContent = len(m.queryResult.Rows) + ui.QueryEditorHeight + 1 // +1 for header row
+		}
+
+		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
+		maxScroll := maxContent - visibleHeight
+		if maxScroll < 0 {
+			maxScroll = 0
+		}
+
+		m.mainScroll = maxScroll
+		return m, nil
+	}
+
+	// Data mode specific navigation and actions
+	if m.mode == model.DataMode {
+		switch msg.String() {
+		case "up", "k":
+			if m.cursorRow > 0 {
+				m.cursorRow--
+
+				// Adjust scroll if cursor moves out of view
+				if m.cursorRow < m.mainScroll {
+					m.mainScroll = m.cursorRow
+				}
+			}
+			return m, nil
+		case "down", "j":
+			// Max rows depends on the current table
+			var maxRows int
+			if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+				table := m.tables[m.activeTableIdx]
+				if data, ok := m.tableData[table]; ok {
+					maxRows = len(data)
+				}
+			}
+
+			if m.cursorRow < maxRows-1 {
+				m.cursorRow++
+
+				// Calculate visible height (approximate based on main height minus borders and header)
+				visibleHeight := m.styles.MainBoxStyle.GetHeight() - 3
+
+				// Adjust scroll if cursor moves out of view
+				if m.cursorRow >= m.mainScroll+visibleHeight {
+					m.mainScroll = m.cursorRow - visibleHeight + 1
+				}
+			}
+			return m, nil
+		case "left", "h":
+			if m.cursorCol > 0 {
+				m.cursorCol--
+			}
+			return m, nil
+		case "right", "l":
+			// Max columns depends on the current table
+			var maxCols int
+			if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+				table := m.tables[m.activeTableIdx]
+				if metadata, ok := m.tableMetadata[table]; ok {
+					maxCols = len(metadata)
+				}
+			}
+
+			if m.cursorCol < maxCols-1 {
+				m.cursorCol++
+			}
+			return m, nil
+		case "enter", "e":
+			// Enter INLINE edit mode for the current cell
+			return m.enterInlineEditMode(), nil
+		case "ctrl+e":
+			// Enter MODAL edit mode for the current cell
+			return m.enterModalEditMode(), nil
+		case "ctrl+n":
+			return m.setCellToNull(), nil
+		case "f":
+			return m, m.followForeignKey()
+		case "o":
+			return m, m.toggleSort(false)
+		case "O":
+			return m, m.toggleSort(true)
+		case "w":
+			m.openRowFilterPrompt(whereSyntax)
+			return m, nil
+		case "F":
+			m.openRowFilterPrompt(conditionSyntax)
+			return m, nil
+		case "E":
+			m.openExportPrompt()
+			return m, nil
+		case "/":
+			m.dataSearching = true
+			m.dataSearch = ""
+			return m, nil
+		case "n":
+			m.jumpToMatch(1, false)
+			return m, nil
+		case "N":
+			m.jumpToMatch(-1, false)
+			return m, nil
+		case "esc":
+			// A search is cleared before the row filter
+			if m.dataSearch != "" {
+				m.dataSearch = ""
+				return m, nil
+			}
+			return m, m.clearRowFilter()
+		case "a":
+			return m.insertRow(), nil
+		case "delete", "ctrl+d":
+			return m.promptDeleteRow(), nil
+		case "ctrl+s":
+			// Review staged changes before committing
+			if m.pendingChangeCount() == 0 {
+				m.statusMessage = "No pending changes"
+				return m, nil
+			}
+			m.showReview = true
+			m.reviewScroll = 0
+			return m, nil
+		}
+	}
+
+	if m.mode == model.QueryMode {
+		return m.handleQueryResultKeys(msg)
+	}
+
+	if m.mode == model.RelationsMode {
+		return m.handleRelationsKeys(msg)
+	}
+
+	if m.mode == model.DDLMode {
+		switch msg.String() {
+		case "up", "k":
+			if m.mainScroll > 0 {
+				m.mainScroll--
+			}
+		case "down", "j":
+			if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+				lines := ui.DDLLines(m.tableDDL[m.tables[m.activeTableIdx]])
+				if m.mainScroll < len(lines)-1 {
+					m.mainScroll++
+				}
+			}
+		}
+		return m, nil
 	}
-	return m.tables
+
+	return m, nil
 }
 
-// Handle keys when in edit mode
-func (m *AppModel) handleEditModeKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// handleObjectKeys scrolls the definition of the object shown in the right panel
+func (m *AppModel) handleObjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	lines := len(ui.ObjectLines(*m.activeObject))
+	visibleHeight := m.styles.MainBoxStyle.GetHeight() - 6
+
 	switch msg.String() {
-	case "esc":
-		// Cancel edit
-		m.editing = false
-		m.editBuffer = ""
-		return m, nil
+	case "up", "k":
+		m.mainScroll--
+	case "down", "j":
+		m.mainScroll++
+	case "pgup":
+		m.mainScroll -= visibleHeight
+	case "pgdown":
+		m.mainScroll += visibleHeight
+	case "home":
+		m.mainScroll = 0
+	case "end":
+		m.mainScroll = lines - visibleHeight
+	}
+	m.mainScroll = max(0, min(m.mainScroll, lines-1))
+	return m, nil
+}
+
+// activeRelations returns the outgoing and incoming foreign keys of the active table
+func (m *AppModel) activeRelations() (string, []model.ForeignKey, []model.ForeignKey) {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return "", nil, nil
+	}
+	table := m.tables[m.activeTableIdx]
+	outgoing, incoming := model.SplitForeignKeys(table, m.tableForeignKeys[table])
+	return table, outgoing, incoming
+}
 
+// Handle keys in the Relations view
+func (m *AppModel) handleRelationsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, outgoing, incoming := m.activeRelations()
+	count := len(outgoing) + len(incoming)
+
+	switch msg.String() {
+	case "up", "k":
+		if m.relationIdx > 0 {
+			m.relationIdx--
+		}
+	case "down", "j":
+		if m.relationIdx < count-1 {
+			m.relationIdx++
+		}
 	case "enter":
-		// Submit edit
-		m.applyEdit()
-		m.editing = false
-		m.editBuffer = ""
-		return m, nil
+		// Jump to the table on the other side of the selected key
+		if m.relationIdx < len(outgoing) {
+			return m, m.openTable(outgoing[m.relationIdx].RefTable)
+		} else if m.relationIdx < count {
+			return m, m.openTable(incoming[m.relationIdx-len(outgoing)].Table)
+		}
+	}
 
-	case "backspace":
-		// Delete last character
-		if len(m.editBuffer) > 0 {
-			m.editBuffer = m.editBuffer[:len(m.editBuffer)-1]
+	// Scroll the selected key into view, as paging may have moved away from it
+	visibleRows := max(1, m.styles.MainBoxStyle.GetHeight()-7)
+	line := ui.RelationLine(outgoing, m.relationIdx)
+	above := min(1, visibleRows-1) // Also show the line above, e.g. the section header, if there is room
+	if line-above < m.mainScroll {
+		m.mainScroll = max(0, line-above)
+	} else if line >= m.mainScroll+visibleRows {
+		m.mainScroll = line - visibleRows + 1
+	}
+	return m, nil
+}
+
+// openTable jumps to a table by name, keeping its row filter and the current view
+func (m *AppModel) openTable(name string) tea.Cmd {
+	return m.navigateTo(name, m.tableFilter[name], m.mode)
+}
+
+// selectTable selects a table by name in the sidebar, clearing the table filter so
+// the selection is visible. It returns the table's index, or -1 if there is none.
+func (m *AppModel) selectTable(name string) int {
+	idx := -1
+	for i, table := range m.tables {
+		if table == name {
+			idx = i
+			break
 		}
-		return m, nil
+	}
+	if idx < 0 {
+		m.statusMessage = fmt.Sprintf("Table %s is not in this database", name)
+		return -1
+	}
 
-	default:
-		// Only add printable characters (ASCII 32-126) to the edit buffer
-		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
-			m.editBuffer += msg.String()
+	m.filtering = false
+	m.filterBuffer = ""
+	m.filteredTables = nil
+	m.filteredObjects = nil
+	m.matchPositions = make(map[string][]int)
+
+	for i, row := range m.sidebarRows() {
+		if row.table == name {
+			m.selectSidebarRow(i, 1)
+			break
 		}
-		return m, nil
 	}
+	return idx
 }
 
-// Handle keys when focus is on the left panel (table list)
-func (m *AppModel) handleLeftPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// navigateTo shows a table with the given row filter and view, remembering the
+// current position so it can be returned to
+func (m *AppModel) navigateTo(name string, filter model.RowFilter, mode model.ViewMode) tea.Cmd {
+	if m.tableLoading[name] {
+		m.statusMessage = fmt.Sprintf("%s is still loading", name)
+		return nil
+	}
+
+	var entry navEntry
+	hasEntry := m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables)
+	if hasEntry {
+		table := m.tables[m.activeTableIdx]
+		entry = navEntry{
+			table:      table,
+			filter:     m.tableFilter[table],
+			mode:       m.mode,
+			offset:     m.tableOffset[table],
+			cursorRow:  m.cursorRow,
+			cursorCol:  m.cursorCol,
+			mainScroll: m.mainScroll,
+		}
+	}
+
+	idx := m.selectTable(name)
+	if idx < 0 {
+		return nil
+	}
+	if hasEntry {
+		m.navHistory = append(m.navHistory, entry)
+	}
+
+	changed := !reflect.DeepEqual(m.tableFilter[name], filter)
+	m.tableFilter[name] = filter
+	m.mode = mode
+
+	cmd := m.activateTable(idx)
+	if cmd == nil && changed {
+		cmd = m.reloadTable(name, 0, placeTop)
+	}
+	return cmd
+}
+
+// goBack returns to the position saved before the last jump
+func (m *AppModel) goBack() tea.Cmd {
+	if len(m.navHistory) == 0 {
+		m.statusMessage = "Nothing to go back to"
+		return nil
+	}
+
+	entry := m.navHistory[len(m.navHistory)-1]
+	if m.tableLoading[entry.table] {
+		m.statusMessage = fmt.Sprintf("%s is still loading", entry.table)
+		return nil
+	}
+
+	idx := m.selectTable(entry.table)
+	if idx < 0 {
+		return nil
+	}
+	m.navHistory = m.navHistory[:len(m.navHistory)-1]
+
+	changed := !reflect.DeepEqual(m.tableFilter[entry.table], entry.filter)
+	m.tableFilter[entry.table] = entry.filter
+	m.mode = entry.mode
+
+	// A table that is not loaded yet is fetched with its filter by activateTable.
+	// The reload goes first, so a DDL view waits for it and loads once it is done.
+	var reload tea.Cmd
+	if _, loaded := m.tableMetadata[entry.table]; loaded && (changed || m.tableOffset[entry.table] != entry.offset) {
+		reload = m.reloadTable(entry.table, entry.offset, placeKeep)
+	}
+	cmd := m.activateTable(idx)
+	m.cursorRow = entry.cursorRow
+	m.cursorCol = entry.cursorCol
+	m.mainScroll = entry.mainScroll
+	return tea.Batch(reload, cmd)
+}
+
+// followForeignKey opens the row referenced by the foreign key under the data cursor
+func (m *AppModel) followForeignKey() tea.Cmd {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return nil
+	}
+	table := m.tables[m.activeTableIdx]
+	metadata := m.tableMetadata[table]
+	data := m.tableData[table]
+	if m.cursorCol >= len(metadata) || m.cursorRow >= len(data) {
+		return nil
+	}
+
+	column := metadata[m.cursorCol].Name
+	outgoing, _ := model.SplitForeignKeys(table, m.tableForeignKeys[table])
+	for _, fk := range outgoing {
+		if !slices.Contains(fk.Columns, column) {
+			continue
+		}
+
+		filter := model.RowFilter{Equal: make(model.RowData, len(fk.Columns))}
+		for i, col := range fk.Columns {
+			val := data[m.cursorRow][col]
+			if val == nil {
+				m.statusMessage = fmt.Sprintf("%s is NULL and references no row", col)
+				return nil
+			}
+			filter.Equal[fk.RefColumns[i]] = val
+		}
+
+		cmd := m.navigateTo(fk.RefTable, filter, model.DataMode)
+		if m.statusMessage == "" {
+			m.statusMessage = "Press b to go back"
+		}
+		return cmd
+	}
+
+	m.statusMessage = fmt.Sprintf("%s is not part of a foreign key", column)
+	return nil
+}
+
+// clearRowFilter shows all rows of the active table again
+func (m *AppModel) clearRowFilter() tea.Cmd {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return nil
+	}
+	table := m.tables[m.activeTableIdx]
+	if m.tableFilter[table].IsEmpty() || m.tableLoading[table] {
+		return nil
+	}
+
+	delete(m.tableFilter, table)
+	m.cursorRow = 0
+	m.mainScroll = 0
+	return m.reloadTable(table, 0, placeTop)
+}
+
+// rowFilterSyntax is the syntax a row filter is typed in
+type rowFilterSyntax string
+
+// Constants for row filter syntaxes
+const (
+	whereSyntax     rowFilterSyntax = "WHERE"
+	conditionSyntax rowFilterSyntax = "column op value"
+)
+
+// openRowFilterPrompt starts typing a row filter for the active table. A SQL
+// condition starts from the one in use; a simple condition starts from the cell
+// under the cursor.
+func (m *AppModel) openRowFilterPrompt(syntax rowFilterSyntax) {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return
+	}
+	table := m.tables[m.activeTableIdx]
+	if m.tableLoading[table] {
+		m.statusMessage = fmt.Sprintf("%s is still loading", table)
+		return
+	}
+
+	m.rowFilterPrompt = syntax
+	m.rowFilterBuffer = ""
+	switch syntax {
+	case whereSyntax:
+		if filter := m.tableFilter[table]; len(filter.Args) == 0 {
+			m.rowFilterBuffer = filter.Where
+		}
+	case conditionSyntax:
+		metadata := m.tableMetadata[table]
+		data := m.tableData[table]
+		if m.cursorCol >= len(metadata) {
+			return
+		}
+		column := metadata[m.cursorCol].Name
+		if strings.ContainsAny(column, " \t") {
+			column = `"` + column + `"`
+		}
+		if m.cursorRow >= len(data) {
+			m.rowFilterBuffer = column + " = "
+		} else if val := data[m.cursorRow][metadata[m.cursorCol].Name]; val == nil {
+			m.rowFilterBuffer = column + " IS NULL"
+		} else {
+			m.rowFilterBuffer = column + " = " + model.FormatValue(val)
+		}
+	}
+}
+
+// handleRowFilterPromptKeys edits the row filter being typed and applies it on Enter
+func (m *AppModel) handleRowFilterPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		// Clear filter if one is applied
-		if m.filteredTables != nil {
-			m.filterBuffer = ""
-			m.filteredTables = nil
-			m.selectedIdx = 0 // Reset selection to the first item
-		}
+		m.rowFilterPrompt = ""
+		m.rowFilterBuffer = ""
 		return m, nil
-	case "/":
-		// Activate filter mode
-		m.filtering = true
-		m.filterBuffer = ""
-		m.filteredTables = nil
+	case "enter":
+		return m, m.applyRowFilter()
+	case "backspace":
+		if len(m.rowFilterBuffer) > 0 {
+			runes := []rune(m.rowFilterBuffer)
+			m.rowFilterBuffer = string(runes[:len(runes)-1])
+		}
 		return m, nil
-	case "up", "k":
-		if m.selectedIdx > 0 {
-			m.selectedIdx--
+	}
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		m.rowFilterBuffer += string(msg.Runes)
+	case tea.KeySpace:
+		m.rowFilterBuffer += " "
+	}
+	return m, nil
+}
+
+// applyRowFilter replaces the typed part of the active table's row filter with the
+// prompt's condition and reloads the first page. An empty condition removes it.
+func (m *AppModel) applyRowFilter() tea.Cmd {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		m.rowFilterPrompt = ""
+		return nil
+	}
+	table := m.tables[m.activeTableIdx]
+
+	filter := m.tableFilter[table]
+	filter.Where, filter.Args = "", nil
+	if input := strings.TrimSpace(m.rowFilterBuffer); input != "" {
+		if m.rowFilterPrompt == conditionSyntax {
+			where, args, err := db.ParseCondition(m.dbManager.Dialect(), m.tableMetadata[table], input)
+			if err != nil {
+				// Keep the prompt open so the condition can be corrected
+				m.statusMessage = err.Error()
+				return nil
+			}
+			filter.Where, filter.Args = where, args
+		} else {
+			filter.Where = input
+		}
+	}
+
+	m.rowFilterPrompt = ""
+	m.rowFilterBuffer = ""
+	if m.tableLoading[table] {
+		m.statusMessage = fmt.Sprintf("%s is still loading", table)
+		return nil
+	}
+
+	m.rowFilterRestore[table] = m.tableFilter[table]
+	m.tableFilter[table] = filter
+	m.cursorRow = 0
+	m.mainScroll = 0
+	return m.reloadTable(table, 0, placeTop)
+}
+
+// reloadTable fetches the page of a table at offset again, with its current row filter
+// and sort order
+func (m *AppModel) reloadTable(table string, offset int, placement cursorPlacement) tea.Cmd {
+	filter, sorts := m.tableFilter[table], m.tableSort[table]
+	return m.startLoading(table, func(ctx context.Context, load int) tea.Cmd {
+		return loadPageCmd(ctx, load, m.dbManager, table, filter, sorts, offset, placement)
+	})
+}
+
+// toggleSort cycles the sort order of the column under the cursor and reloads the
+// first page. With multi the column is added to, rather than replaces, the others.
+func (m *AppModel) toggleSort(multi bool) tea.Cmd {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return nil
+	}
+	table := m.tables[m.activeTableIdx]
+	metadata := m.tableMetadata[table]
+	if m.cursorCol >= len(metadata) {
+		return nil
+	}
+	if m.tableLoading[table] {
+		m.statusMessage = fmt.Sprintf("%s is still loading", table)
+		return nil
+	}
+
+	m.tableSort[table] = model.ToggleSort(m.tableSort[table], metadata[m.cursorCol].Name, multi)
+	m.cursorRow = 0
+	m.mainScroll = 0
+	return m.reloadTable(table, 0, placeTop)
+}
+
+// exportSource names what an export from the current view writes: the active
+// table's rows, or the query result
+func (m *AppModel) exportSource() string {
+	if m.mode == model.QueryMode {
+		return "query result"
+	}
+	return m.tables[m.activeTableIdx]
+}
+
+// openExportPrompt starts typing the file name to export the active table, with its
+// row filter and sort order, or the query result to
+func (m *AppModel) openExportPrompt() {
+	if m.exporting {
+		m.statusMessage = "Wait for the running export to finish"
+		return
+	}
+
+	name := "result"
+	if m.mode == model.QueryMode {
+		if m.queryResult == nil || !m.queryResult.IsQuery {
+			return
+		}
+	} else {
+		if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+			return
+		}
+		name = m.tables[m.activeTableIdx]
+		if m.tableLoading[name] {
+			m.statusMessage = fmt.Sprintf("%s is still loading", name)
+			return
+		}
+	}
 
-			// Adjust scroll position if selection moves out of view
-			if m.selectedIdx < m.sidebarScroll {
-				m.sidebarScroll = m.selectedIdx
-			}
+	m.exportPrompt = true
+	m.exportBuffer = name + ".csv"
+}
+
+// handleExportPromptKeys edits the export file name and starts the export on Enter
+func (m *AppModel) handleExportPromptKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.exportPrompt = false
+		m.exportBuffer = ""
+		return m, nil
+	case "enter":
+		return m, m.startExport()
+	case "backspace":
+		if len(m.exportBuffer) > 0 {
+			runes := []rune(m.exportBuffer)
+			m.exportBuffer = string(runes[:len(runes)-1])
 		}
 		return m, nil
-	case "down", "j":
-		tablesList := m.getFilteredOrAllTables()
+	}
+
+	switch msg.Type {
+	case tea.KeyRunes:
+		m.exportBuffer += string(msg.Runes)
+	case tea.KeySpace:
+		m.exportBuffer += " "
+	}
+	return m, nil
+}
+
+// startExport writes the export to the typed file, in the format its extension
+// names. Tables are streamed from the server in the background, so the export
+// covers every matching row rather than the loaded page.
+func (m *AppModel) startExport() tea.Cmd {
+	path := config.ExpandHome(strings.TrimSpace(m.exportBuffer))
+	format, err := db.ExportFormatFor(path)
+	if err != nil {
+		// Keep the prompt open to fix the name
+		m.statusMessage = err.Error()
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		m.statusMessage = fmt.Sprintf("%s already exists, choose another name", path)
+		return nil
+	}
+	m.exportPrompt = false
+	m.exportBuffer = ""
+
+	var cmd tea.Cmd
+	if m.mode == model.QueryMode {
+		cmd = exportResultCmd(m.queryResult, path, format)
+	} else {
+		table := m.tables[m.activeTableIdx]
+		filter, sorts := m.tableFilter[table], m.tableSort[table]
+		m.statusMessage = fmt.Sprintf("Exporting %s to %s...", table, path)
+
+		// A large export may take much longer than any single query, so it runs without
+		// the query timeout until it finishes or is cancelled
+		ctx, cancel := context.WithCancel(context.Background())
+		cmd = exportTableCmd(ctx, m.dbManager, table, filter, sorts, path, format)
+		m.exportCancel = cancel
+	}
+
+	idle := !m.busy()
+	m.exporting = true
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
 
-		if m.selectedIdx < len(tablesList)-1 {
-			m.selectedIdx++
+// searchMatch is a cell of the Data view matching the search term, with the
+// positions of the matched characters in its displayed value
+type searchMatch struct {
+	row, col  int
+	positions []int
+}
 
-			// Calculate visible height (approximate based on main height minus borders)
-			visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
+// searchMatches lists the cells of a table's loaded rows that match the search
+// term, row by row
+func (m *AppModel) searchMatches(table string) []searchMatch {
+	if m.dataSearch == "" {
+		return nil
+	}
 
-			// Adjust scroll position if selection moves out of view
-			if m.selectedIdx >= m.sidebarScroll+visibleHeight {
-				m.sidebarScroll = m.selectedIdx - visibleHeight + 1
+	var matches []searchMatch
+	metadata := m.tableMetadata[table]
+	for i, row := range m.tableData[table] {
+		for j, col := range metadata {
+			val, ok := row[col.Name]
+			if !ok {
+				continue
 			}
-		}
-		return m, nil
-	case "enter":
-		// Activate the selected table
-		tablesList := m.getFilteredOrAllTables()
-		if len(tablesList) > 0 && m.selectedIdx < len(tablesList) {
-			// Find the real index in the full tables list
-			selectedTable := tablesList[m.selectedIdx]
-			for i, table := range m.tables {
-				if table == selectedTable {
-					m.activeTableIdx = i
-					break
-				}
+			if score, positions := fuzzyMatch(m.dataSearch, model.FormatValue(val)); score > 0 {
+				matches = append(matches, searchMatch{row: i, col: j, positions: positions})
 			}
-			
-			// Reset main content scroll when changing tables
-			m.mainScroll = 0
-			m.cursorRow = 0
-			m.cursorCol = 0
-		}
-		return m, nil
-	case "pgup":
-		// Page up - scroll up by visible height
-		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
-		m.sidebarScroll -= visibleHeight
-		if m.sidebarScroll < 0 {
-			m.sidebarScroll = 0
 		}
+	}
+	return matches
+}
 
-		// Also move selection if it's now out of view
-		if m.selectedIdx >= m.sidebarScroll+visibleHeight {
-			m.selectedIdx = m.sidebarScroll + visibleHeight - 1
-		} else if m.selectedIdx < m.sidebarScroll {
-			m.selectedIdx = m.sidebarScroll
-		}
-		return m, nil
-	case "pgdown":
-		// Page down - scroll down by visible height
-		tablesList := m.getFilteredOrAllTables()
-		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
-		maxScroll := len(tablesList) - visibleHeight
-		if maxScroll < 0 {
-			maxScroll = 0
+// searchHighlights maps row indices of a table's data to the matched character
+// positions of each matching column
+func (m *AppModel) searchHighlights(table string) map[int]map[string][]int {
+	highlights := make(map[int]map[string][]int)
+	metadata := m.tableMetadata[table]
+	for _, match := range m.searchMatches(table) {
+		if highlights[match.row] == nil {
+			highlights[match.row] = make(map[string][]int)
 		}
+		highlights[match.row][metadata[match.col].Name] = match.positions
+	}
+	return highlights
+}
 
-		m.sidebarScroll += visibleHeight
-		if m.sidebarScroll > maxScroll {
-			m.sidebarScroll = maxScroll
-		}
+// jumpToMatch moves the cursor to the next matching cell in dir, 1 for forward and
+// -1 for backward, wrapping around the loaded rows. With inclusive the cell under
+// the cursor counts as the next match.
+func (m *AppModel) jumpToMatch(dir int, inclusive bool) {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) || m.dataSearch == "" {
+		return
+	}
+	matches := m.searchMatches(m.tables[m.activeTableIdx])
+	if len(matches) == 0 {
+		m.statusMessage = fmt.Sprintf("No matches for %q", m.dataSearch)
+		return
+	}
 
-		// Also move selection if it's now out of view
-		if m.selectedIdx < m.sidebarScroll {
-			m.selectedIdx = m.sidebarScroll
-		} else if m.selectedIdx >= m.sidebarScroll+visibleHeight {
-			m.selectedIdx = m.sidebarScroll + visibleHeight - 1
-			if m.selectedIdx >= len(tablesList) {
-				m.selectedIdx = len(tablesList) - 1
-			}
+	// Matches are in row order, so the first one past the cursor is the next
+	cursor := func(match searchMatch) int {
+		if match.row != m.cursorRow {
+			return match.row - m.cursorRow
 		}
-		return m, nil
-	case "home":
-		// Scroll to top
-		m.sidebarScroll = 0
-		if m.selectedIdx < m.sidebarScroll {
-			m.selectedIdx = m.sidebarScroll
+		return match.col - m.cursorCol
+	}
+	next := -1
+	for i := range matches {
+		idx := i
+		if dir < 0 {
+			idx = len(matches) - 1 - i
 		}
-		return m, nil
-	case "end":
-		// Scroll to bottom
-		tablesList := m.getFilteredOrAllTables()
-		visibleHeight := m.styles.SidebarStyle.GetHeight() - 2
-		maxScroll := len(tablesList) - visibleHeight
-		if maxScroll < 0 {
-			maxScroll = 0
+		if d := cursor(matches[idx]) * dir; d > 0 || (d == 0 && inclusive) {
+			next = idx
+			break
 		}
-		m.sidebarScroll = maxScroll
-
-		if m.selectedIdx < m.sidebarScroll {
-			m.selectedIdx = m.sidebarScroll
+	}
+	if next < 0 {
+		next = 0
+		if dir < 0 {
+			next = len(matches) - 1
 		}
-		return m, nil
+		m.statusMessage = "Search wrapped"
 	}
 
-	return m, nil
+	m.cursorRow, m.cursorCol = matches[next].row, matches[next].col
+
+	// Scroll the match into view
+	visibleHeight := m.styles.MainBoxStyle.GetHeight() - 3
+	if m.cursorRow < m.mainScroll {
+		m.mainScroll = m.cursorRow
+	} else if m.cursorRow >= m.mainScroll+visibleHeight {
+		m.mainScroll = m.cursorRow - visibleHeight + 1
+	}
 }
 
-// Handle keys when focus is on the right panel (table data)
-func (m *AppModel) handleRightPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Tab switching
+// handleDataSearchKeys edits the Data view's search term, moving to the first match
+// from the cursor as it is typed
+func (m *AppModel) handleDataSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "d":
-		m.mode = model.DataMode
-		m.mainScroll = 0 // Reset scroll position when changing view
-		return m, nil
-	case "s":
-		m.mode = model.StructureMode
-		m.mainScroll = 0 // Reset scroll position when changing view
+	case "esc":
+		m.dataSearching = false
+		m.dataSearch = ""
 		return m, nil
-	case "i":
-		m.mode = model.IndicesMode
-		m.mainScroll = 0 // Reset scroll position when changing view
+	case "enter":
+		m.dataSearching = false
+		m.jumpToMatch(1, true)
 		return m, nil
-	}
-
-	// Common scrolling keys for all modes
-	switch msg.String() {
-	case "pgup":
-		// Page up - scroll up by visible height
-		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
-		m.mainScroll -= visibleHeight
-		if m.mainScroll < 0 {
-			m.mainScroll = 0
+	case "backspace":
+		if len(m.dataSearch) > 0 {
+			runes := []rune(m.dataSearch)
+			m.dataSearch = string(runes[:len(runes)-1])
 		}
-		return m, nil
-	case "pgdown":
-		var maxContent int
-		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
-			table := m.tables[m.activeTableIdx]
-			// Determine content height based on mode
-			if m.mode == model.DataMode && m.tableData[table] != nil {
-				maxContent = len(m.tableData[table]) + 1 // +1 for header row
-			} else if m.mode == model.StructureMode && m.tableMetadata[table] != nil {
-				maxContent = len(m.tableMetadata[table]) + 2 // +2 for title and blank line
-			} else if m.mode == model.IndicesMode && m.tableIndices[table] != nil {
-				maxContent = len(m.tableIndices[table]) + 2 // +2 for title and blank line
-			}
+	default:
+		switch msg.Type {
+		case tea.KeyRunes:
+			m.dataSearch += string(msg.Runes)
+		case tea.KeySpace:
+			m.dataSearch += " "
+		default:
+			return m, nil
 		}
+	}
 
-		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
-		m.mainScroll += visibleHeight
+	m.jumpToMatch(1, true)
+	m.statusMessage = ""
+	return m, nil
+}
 
-		// Calculate max scroll position
-		maxScroll := maxContent - visibleHeight
-		if maxScroll < 0 {
-			maxScroll = 0
+// searchStatus summarizes the search term's matches in a table for the status bar
+func (m *AppModel) searchStatus(table string) string {
+	matches := m.searchMatches(table)
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for %q", m.dataSearch)
+	}
+	for i, match := range matches {
+		if match.row == m.cursorRow && match.col == m.cursorCol {
+			return fmt.Sprintf("Match %d of %d for %q, n/N for next/previous", i+1, len(matches), m.dataSearch)
 		}
+	}
+	return fmt.Sprintf("%d matches for %q, n/N for next/previous", len(matches), m.dataSearch)
+}
 
-		if m.mainScroll > maxScroll {
-			m.mainScroll = maxScroll
+// handleDataPaging loads the next or previous page of the active table when a
+// navigation key moves past the loaded rows. It returns true if the key was consumed.
+func (m *AppModel) handleDataPaging(key string) (bool, tea.Cmd) {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return false, nil
+	}
+
+	table := m.tables[m.activeTableIdx]
+	if m.tableLoading[table] {
+		// Ignore paging until the current load finishes
+		return true, nil
+	}
+
+	data := m.tableData[table]
+	offset := m.tableOffset[table]
+	hasMore := m.tableHasMore[table]
+	visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
+	maxScroll := max(0, len(data)+1-visibleHeight)
+
+	var nextOffset int
+	var placement cursorPlacement
+	switch key {
+	case "down", "j":
+		if m.cursorRow < len(data)-1 || !hasMore {
+			return false, nil
 		}
-		return m, nil
+		nextOffset, placement = offset+pageSize, placeTop
+	case "up", "k":
+		if m.cursorRow > 0 || offset == 0 {
+			return false, nil
+		}
+		nextOffset, placement = max(0, offset-pageSize), placeBottom
+	case "pgdown":
+		if m.mainScroll < maxScroll || !hasMore {
+			return false, nil
+		}
+		nextOffset, placement = offset+pageSize, placeTop
+	case "pgup":
+		if m.mainScroll > 0 || offset == 0 {
+			return false, nil
+		}
+		nextOffset, placement = max(0, offset-pageSize), placeBottom
 	case "home":
-		m.mainScroll = 0
-		return m, nil
-	case "end":
-		var maxContent int
-		if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
-			table := m.tables[m.activeTableIdx]
-			// Determine content height based on mode
-			if m.mode == model.DataMode && m.tableData[table] != nil {
-				maxContent = len(m.tableData[table]) + 1 // +1 for header row
-			} else if m.mode == model.StructureMode && m.tableMetadata[table] != nil {
-				maxContent = len(m.tableMetadata[table]) + 2 // +2 for title and blank line
-			} else if m.mode == model.IndicesMode && m.tableIndices[table] != nil {
-				maxContent = len(m.tableIndices[table]) + 2 // +2 for title and blank line
-			}
+		if offset == 0 {
+			return false, nil
 		}
-
-		visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
-		maxScroll := maxContent - visibleHeight
-		if maxScroll < 0 {
-			maxScroll = 0
+		nextOffset, placement = 0, placeTop
+	case "end":
+		if !hasMore {
+			return false, nil
 		}
-
-		m.mainScroll = maxScroll
-		return m, nil
+		nextOffset, placement = lastPage, placeBottom
+	default:
+		return false, nil
 	}
 
-	// Data mode specific navigation and actions
-	if m.mode == model.DataMode {
-		switch msg.String() {
-		case "up", "k":
-			if m.cursorRow > 0 {
-				m.cursorRow--
+	return true, m.reloadTable(table, nextOffset, placement)
+}
 
-				// Adjust scroll if cursor moves out of view
-				if m.cursorRow < m.mainScroll {
-					m.mainScroll = m.cursorRow
-				}
-			}
-			return m, nil
-		case "down", "j":
-			// Max rows depends on the current table
-			var maxRows int
-			if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
-				table := m.tables[m.activeTableIdx]
-				if data, ok := m.tableData[table]; ok {
-					maxRows = len(data)
-				}
-			}
+// storePage replaces the loaded rows of a table with a freshly fetched page
+func (m *AppModel) storePage(table string, offset int, data []model.RowData, estimate int64) {
+	m.tableOffset[table] = offset
+	m.tableHasMore[table] = len(data) == pageSize
+	m.tableRowEstimate[table] = estimate
+	m.tableData[table] = m.overlayChanges(table, data)
+}
 
-			if m.cursorRow < maxRows-1 {
-				m.cursorRow++
+// placeCursor moves the data cursor after a page load
+func (m *AppModel) placeCursor(placement cursorPlacement) {
+	rowCount := len(m.tableData[m.tables[m.activeTableIdx]])
+	visibleHeight := m.styles.MainBoxStyle.GetHeight() - 2
 
-				// Calculate visible height (approximate based on main height minus borders and header)
-				visibleHeight := m.styles.MainBoxStyle.GetHeight() - 3
+	switch placement {
+	case placeTop:
+		m.cursorRow = 0
+		m.mainScroll = 0
+	case placeBottom:
+		m.cursorRow = max(0, rowCount-1)
+		m.mainScroll = max(0, rowCount+1-visibleHeight)
+	default:
+		m.clampCursor()
+	}
+}
 
-				// Adjust scroll if cursor moves out of view
-				if m.cursorRow >= m.mainScroll+visibleHeight {
-					m.mainScroll = m.cursorRow - visibleHeight + 1
-				}
-			}
-			return m, nil
-		case "left", "h":
-			if m.cursorCol > 0 {
-				m.cursorCol--
-			}
-			return m, nil
-		case "right", "l":
-			// Max columns depends on the current table
-			var maxCols int
-			if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
-				table := m.tables[m.activeTableIdx]
-				if metadata, ok := m.tableMetadata[table]; ok {
-					maxCols = len(metadata)
-				}
+// overlayChanges re-attaches staged changes to freshly fetched rows, so pending
+// edits and deletions stay visible when a page is loaded again. Pending inserts
+// are shown after the fetched rows of the last page only.
+func (m *AppModel) overlayChanges(table string, data []model.RowData) []model.RowData {
+	lastPage := !m.tableHasMore[table]
+	changes := m.pendingChanges[table]
+	for i, change := range changes {
+		if change.Kind == model.ChangeInsert {
+			if lastPage {
+				data = append(data, change.Row)
 			}
+			continue
+		}
 
-			if m.cursorCol < maxCols-1 {
-				m.cursorCol++
+		for _, row := range data {
+			if !keyMatches(change.Key, row) {
+				continue
 			}
-			return m, nil
-		case "enter", "e":
-			// Enter INLINE edit mode for the current cell
-			return m.enterInlineEditMode(), nil
-		case "ctrl+e":
-			// Enter MODAL edit mode for the current cell
-			return m.enterModalEditMode(), nil
-		case "ctrl+n":
-			return m.setCellToNull(), nil
+			changes[i].Row = row
+			if change.Kind == model.ChangeUpdate {
+				row[change.Column] = change.NewValue
+			}
+			break
 		}
 	}
+	return data
+}
 
-	return m, nil
+// keyMatches reports whether a row holds the given key column values
+func keyMatches(key model.RowData, row model.RowData) bool {
+	for col, val := range key {
+		if !reflect.DeepEqual(row[col], val) {
+			return false
+		}
+	}
+	return true
 }
 
 // Enter INLINE edit mode for the current cell
@@ -829,6 +2732,9 @@ func (m *AppModel) enterInlineEditMode() tea.Model {
 	if m.mode != model.DataMode {
 		return m
 	}
+	if m.writeBlocked() {
+		return m
+	}
 
 	// Set editing flag and prepare edit buffer
 	m.editing = true
@@ -845,6 +2751,9 @@ func (m *AppModel) enterModalEditMode() tea.Model {
 	if m.mode != model.DataMode {
 		return m
 	}
+	if m.writeBlocked() {
+		return m
+	}
 
 	// Set editing and modal flags, prepare buffer, store target
 	m.editing = true
@@ -869,7 +2778,9 @@ func (m *AppModel) handleEditModalKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "enter":
 		// Submit modal edit
-		m.applyEdit() // applyEdit will now use modalTargetRow/Col
+		if !m.applyEdit() { // applyEdit will now use modalTargetRow/Col
+			return m, nil
+		}
 		m.editing = false
 		m.showEditModal = false
 		m.editBuffer = ""
@@ -942,8 +2853,9 @@ func (m *AppModel) getCurrentCellValue() string {
 	return ""
 }
 
-// Apply the edit to the current cell
-func (m *AppModel) applyEdit() {
+// Apply the edit to the current cell. Returns false, with the reason in the status
+// bar, when the input does not fit the column and the editor should stay open.
+func (m *AppModel) applyEdit() bool {
 	targetRow := m.cursorRow
 	targetCol := m.cursorCol
 
@@ -954,7 +2866,7 @@ func (m *AppModel) applyEdit() {
 	}
 
 	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
-		return
+		return true
 	}
 
 	table := m.tables[m.activeTableIdx]
@@ -965,74 +2877,537 @@ func (m *AppModel) applyEdit() {
 
 	// Use targetRow/targetCol for validation and access
 	if !dataOk || !metaOk || targetRow >= len(data) || targetCol >= len(metadata) {
-		return
+		return true
 	}
 
 	colMeta := metadata[targetCol] // Get the specific column metadata
 	colName := colMeta.Name
-	colType := colMeta.Type
-	isNullable := colMeta.Nullable
 
 	// Get the row
 	row := data[targetRow]
 
-	// Parse the edited value based on column type
-	var newValue interface{}
+	// Parse the edited value based on column type; NULL is only written by Ctrl+N
+	newValue, err := model.ParseCellValue(colMeta, m.editBuffer)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Invalid value for %s: %v", colName, err)
+		if colMeta.Nullable && strings.TrimSpace(m.editBuffer) == "" {
+			m.statusMessage += " (Ctrl+N sets NULL)"
+		}
+		return false
+	}
+
+	if !m.stageChange(table, metadata, row, colName, newValue) {
+		return true
+	}
 
-	// Handle empty input for nullable columns -> treat as empty string
-	if m.editBuffer == "" && isNullable {
-		newValue = ""
-	} else {
-		// Parse based on data type (existing logic)
-		if strings.Contains(colType, "int") {
-			if val, err := model.ParseInt(m.editBuffer); err == nil {
-				newValue = val
-			} else if isNullable {
-				// If parsing fails and column is nullable, set to nil (or keep original?)
-				// Let's keep nil for now as empty string doesn't make sense for int
-				newValue = nil
+	// Update the value in memory (committed later from the change set)
+	row[colName] = newValue
+	data[targetRow] = row // Use targetRow
+	m.tableData[table] = data
+	return true
+}
+
+// writeBlocked reports, and explains in the status bar, that the connection is read-only,
+// a commit is in flight, or the active table is a view or another object that cannot
+// be edited
+func (m *AppModel) writeBlocked() bool {
+	if m.dbConfig.ReadOnly {
+		m.statusMessage = "Connection is read-only"
+		return true
+	}
+	if m.committing {
+		m.statusMessage = "Wait for the commit to finish"
+		return true
+	}
+
+	if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+		table := m.tables[m.activeTableIdx]
+		if tableType := m.tableTypes[table]; !tableType.Writable() {
+			m.statusMessage = fmt.Sprintf("%s is a %s and cannot be edited", table, strings.ToLower(string(tableType)))
+			return true
+		}
+	}
+	return false
+}
+
+// stageChange records a cell modification in the table's pending change set.
+// Repeated edits of the same cell are merged, and an edit that restores the
+// original value drops the change entirely.
+func (m *AppModel) stageChange(table string, metadata []model.ColumnMetadata, row model.RowData, colName string, value interface{}) bool {
+	changes := m.pendingChanges[table]
+
+	// The first change of the row holds the key the database knows it by
+	var original model.RowData
+	for i, change := range changes {
+		if !change.Row.Same(row) {
+			continue
+		}
+
+		switch change.Kind {
+		case model.ChangeInsert:
+			// Inserted rows are written with their final values on commit
+			return true
+		case model.ChangeDelete:
+			m.statusMessage = "Row is marked for deletion"
+			return false
+		}
+
+		if original == nil {
+			original = change.Key
+		}
+		if change.Column == colName {
+			if reflect.DeepEqual(change.OldValue, value) {
+				changes = append(changes[:i], changes[i+1:]...)
 			} else {
-				newValue = row[colName] // Keep original if not nullable and parse fails
+				changes[i].NewValue = value
 			}
-		} else if strings.Contains(colType, "float") ||
-			strings.Contains(colType, "double") ||
-			strings.Contains(colType, "decimal") {
-			if val, err := model.ParseFloat(m.editBuffer); err == nil {
-				newValue = val
-			} else if isNullable {
-				newValue = nil // Keep nil for parse errors on nullable numerics
-			} else {
-				newValue = row[colName]
+			rebaseKeys(changes, row, original)
+			m.pendingChanges[table] = changes
+			return true
+		}
+	}
+
+	// First change for this row: its current values are what the database holds
+	if original == nil {
+		var err error
+		original, err = db.RowKey(table, metadata, row)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot edit: %v", err)
+			return false
+		}
+	}
+
+	if reflect.DeepEqual(row[colName], value) {
+		return true
+	}
+
+	changes = append(changes, model.Change{
+		Kind:     model.ChangeUpdate,
+		Table:    table,
+		Row:      row,
+		Column:   colName,
+		OldValue: row[colName],
+		NewValue: value,
+	})
+	rebaseKeys(changes, row, original)
+	m.pendingChanges[table] = changes
+	return true
+}
+
+// rebaseKeys sets the key of each staged UPDATE of row to the key values the row
+// has once the changes before it are applied, starting from original. An edit
+// made after one of a key column then still finds the row.
+func rebaseKeys(changes []model.Change, row model.RowData, original model.RowData) {
+	key := original
+	for i := range changes {
+		change := &changes[i]
+		if change.Kind != model.ChangeUpdate || !change.Row.Same(row) {
+			continue
+		}
+
+		change.Key = key
+		if _, isKey := key[change.Column]; isKey {
+			next := make(model.RowData, len(key))
+			for col, val := range key {
+				next[col] = val
 			}
-		} else if strings.Contains(colType, "bool") ||
-			strings.Contains(colType, "tinyint(1)") {
-			lowerBuffer := strings.ToLower(m.editBuffer)
-			if lowerBuffer == "true" || lowerBuffer == "yes" || lowerBuffer == "1" {
-				newValue = true
-			} else if lowerBuffer == "false" || lowerBuffer == "no" || lowerBuffer == "0" {
-				newValue = false
-			} else if isNullable {
-				newValue = nil // Keep nil for parse errors on nullable bools
-			} else {
-				newValue = false // Default to false for non-nullable bools on parse error
+			next[change.Column] = change.NewValue
+			key = next
+		}
+	}
+}
+
+// insertRow appends a blank row pre-filled with column defaults and stages its INSERT
+func (m *AppModel) insertRow() tea.Model {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return m
+	}
+	if m.writeBlocked() {
+		return m
+	}
+
+	table := m.tables[m.activeTableIdx]
+	metadata, ok := m.tableMetadata[table]
+	if !ok || len(metadata) == 0 {
+		return m
+	}
+
+	row := make(model.RowData, len(metadata))
+	for _, col := range metadata {
+		row[col.Name] = defaultCellValue(col)
+	}
+
+	m.tableData[table] = append(m.tableData[table], row)
+	m.pendingChanges[table] = append(m.pendingChanges[table], model.Change{
+		Kind:  model.ChangeInsert,
+		Table: table,
+		Row:   row,
+	})
+
+	// Move the cursor to the new row
+	m.cursorRow = len(m.tableData[table]) - 1
+	m.cursorCol = 0
+	visibleHeight := m.styles.MainBoxStyle.GetHeight() - 3
+	if m.cursorRow >= m.mainScroll+visibleHeight {
+		m.mainScroll = m.cursorRow - visibleHeight + 1
+	}
+
+	m.statusMessage = fmt.Sprintf("New row added to %s", table)
+	return m
+}
+
+// defaultCellValue converts a column default into a value for a freshly inserted row.
+// Expression defaults are left nil so the server evaluates them on insert.
+func defaultCellValue(col model.ColumnMetadata) interface{} {
+	defaultStr, ok := col.Default.(string)
+	if !ok {
+		return col.Default
+	}
+
+	upper := strings.ToUpper(defaultStr)
+	if strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.HasSuffix(upper, ")") {
+		return nil
+	}
+
+	// Read the default as if it was typed into the cell, keeping it as text if it does not parse
+	if val, err := model.ParseCellValue(col, defaultStr); err == nil {
+		return val
+	}
+	return defaultStr
+}
+
+// promptDeleteRow asks for confirmation before staging the deletion of the row under the cursor
+func (m *AppModel) promptDeleteRow() tea.Model {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return m
+	}
+	if m.writeBlocked() {
+		return m
+	}
+
+	table := m.tables[m.activeTableIdx]
+	data := m.tableData[table]
+	if m.cursorRow >= len(data) {
+		return m
+	}
+
+	// Pressing delete on a row already marked for deletion restores it
+	changes := m.pendingChanges[table]
+	for i, change := range changes {
+		if change.Kind == model.ChangeDelete && change.Row.Same(data[m.cursorRow]) {
+			m.pendingChanges[table] = append(changes[:i], changes[i+1:]...)
+			m.statusMessage = fmt.Sprintf("Row %d restored", m.cursorRow+1)
+			return m
+		}
+	}
+
+	m.confirmDelete = true
+	m.statusMessage = fmt.Sprintf("Delete row %d from %s? (y/n)", m.cursorRow+1, table)
+	return m
+}
+
+// Handle keys while the delete confirmation prompt is shown
+func (m *AppModel) handleDeleteConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirmDelete = false
+
+	switch msg.String() {
+	case "y", "Y":
+		m.deleteRow()
+	default:
+		m.statusMessage = "Delete cancelled"
+	}
+
+	return m, nil
+}
+
+// deleteRow stages the deletion of the row under the cursor, replacing any
+// pending edits of that row. Rows that were never committed are simply dropped.
+func (m *AppModel) deleteRow() {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return
+	}
+
+	table := m.tables[m.activeTableIdx]
+	data := m.tableData[table]
+	if m.cursorRow >= len(data) {
+		return
+	}
+	row := data[m.cursorRow]
+
+	var key model.RowData
+	var remaining []model.Change
+	for _, change := range m.pendingChanges[table] {
+		if !change.Row.Same(row) {
+			remaining = append(remaining, change)
+			continue
+		}
+
+		switch change.Kind {
+		case model.ChangeInsert:
+			// Drop the uncommitted row together with its INSERT
+			m.pendingChanges[table] = removeRowChanges(m.pendingChanges[table], row)
+			m.tableData[table] = append(data[:m.cursorRow], data[m.cursorRow+1:]...)
+			if m.cursorRow > 0 && m.cursorRow >= len(m.tableData[table]) {
+				m.cursorRow--
 			}
-		} else {
-			// Default to string for other types (VARCHAR, TEXT, etc.)
-			// Empty string for nullable is handled above.
-			// If the buffer is empty and not nullable, set empty string
-			if m.editBuffer == "" && !isNullable {
-				newValue = "" // Set non-nullable strings to empty string if buffer is empty
-			} else {
-				// Treat the buffer content as a string, including "NULL" or "null"
-				newValue = m.editBuffer
+			m.statusMessage = "New row removed"
+			return
+		case model.ChangeUpdate:
+			// Revert the edit so the row shows what is being deleted, which the
+			// database knows by the key of the first edit
+			row[change.Column] = change.OldValue
+			if key == nil {
+				key = change.Key
 			}
 		}
 	}
 
-	// Update the value in memory (not in the database)
-	row[colName] = newValue
-	data[targetRow] = row // Use targetRow
-	m.tableData[table] = data
+	if key == nil {
+		var err error
+		key, err = db.RowKey(table, m.tableMetadata[table], row)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot delete: %v", err)
+			return
+		}
+	}
+
+	m.pendingChanges[table] = append(remaining, model.Change{
+		Kind:  model.ChangeDelete,
+		Table: table,
+		Key:   key,
+		Row:   row,
+	})
+	m.statusMessage = fmt.Sprintf("Row %d marked for deletion", m.cursorRow+1)
+}
+
+// removeRowChanges returns the changes that do not apply to the given row
+func removeRowChanges(changes []model.Change, row model.RowData) []model.Change {
+	var remaining []model.Change
+	for _, change := range changes {
+		if !change.Row.Same(row) {
+			remaining = append(remaining, change)
+		}
+	}
+	return remaining
+}
+
+// allPendingChanges returns every staged change, grouped by table in sidebar order
+func (m *AppModel) allPendingChanges() []model.Change {
+	var changes []model.Change
+	for _, table := range m.tables {
+		changes = append(changes, m.pendingChanges[table]...)
+	}
+	return changes
+}
+
+// pendingChangeCount returns the number of staged changes across all tables
+func (m *AppModel) pendingChangeCount() int {
+	count := 0
+	for _, changes := range m.pendingChanges {
+		count += len(changes)
+	}
+	return count
+}
+
+// pendingInsertCount returns the number of rows staged for insertion into a table
+func (m *AppModel) pendingInsertCount(table string) int {
+	count := 0
+	for _, change := range m.pendingChanges[table] {
+		if change.Kind == model.ChangeInsert {
+			count++
+		}
+	}
+	return count
+}
+
+// pendingStatements renders the SQL for every staged change for the review screen
+func (m *AppModel) pendingStatements() []string {
+	changes := m.allPendingChanges()
+	statements := make([]string, len(changes))
+	for i, change := range changes {
+		query, args, err := db.BuildStatement(m.dbManager.Dialect(), change)
+		if err != nil {
+			statements[i] = fmt.Sprintf("-- %v", err)
+			continue
+		}
+		statements[i] = db.InlineStatement(query, args)
+	}
+	return statements
+}
+
+// commitChanges starts applying all staged changes in a single transaction. Edits
+// are blocked until it reports back.
+func (m *AppModel) commitChanges() tea.Cmd {
+	changes := m.allPendingChanges()
+	if len(changes) == 0 || m.committing {
+		return nil
+	}
+
+	ctx, cancel := m.newContext()
+	cmd := commitChangesCmd(ctx, m.dbManager, changes)
+
+	idle := !m.busy()
+	m.committing = true
+	m.commitCancel = cancel
+	m.statusMessage = fmt.Sprintf("Committing %d change(s)...", len(changes))
+	if idle {
+		return tea.Batch(cmd, spinnerTick())
+	}
+	return cmd
+}
+
+// finishCommit drops committed changes from the change set, or keeps them staged
+// when the transaction failed
+func (m *AppModel) finishCommit(msg changesCommittedMsg) tea.Cmd {
+	m.committing = false
+	if m.commitCancel != nil {
+		m.commitCancel()
+		m.commitCancel = nil
+	}
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Commit failed, nothing applied: %s", m.describeError(msg.err))
+		return nil
+	}
+
+	// Drop deleted rows from memory
+	for _, change := range msg.changes {
+		if change.Kind == model.ChangeDelete {
+			m.tableData[change.Table] = removeRow(m.tableData[change.Table], change.Row)
+		}
+	}
+	m.pendingChanges = make(map[string][]model.Change)
+
+	m.clampCursor()
+	m.statusMessage = fmt.Sprintf("Committed %d change(s), %d row(s) affected", len(msg.changes), msg.affected)
+
+	// Reload touched tables so generated values (auto-increment keys, defaults) show up
+	var cmds []tea.Cmd
+	for _, table := range m.tables {
+		if changesTouch(msg.changes, table) {
+			cmds = append(cmds, m.reloadTable(table, m.tableOffset[table], placeKeep))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// discardChanges reverts all staged changes in memory
+func (m *AppModel) discardChanges() {
+	changes := m.allPendingChanges()
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		switch change.Kind {
+		case model.ChangeUpdate:
+			change.Row[change.Column] = change.OldValue
+		case model.ChangeInsert:
+			m.tableData[change.Table] = removeRow(m.tableData[change.Table], change.Row)
+		}
+	}
+
+	m.pendingChanges = make(map[string][]model.Change)
+	m.clampCursor()
+	m.statusMessage = fmt.Sprintf("Discarded %d change(s)", len(changes))
+}
+
+// removeRow returns the data without the given row
+func removeRow(data []model.RowData, row model.RowData) []model.RowData {
+	for i, r := range data {
+		if r.Same(row) {
+			return append(data[:i], data[i+1:]...)
+		}
+	}
+	return data
+}
+
+// changesTouch reports whether any of the changes targets the table
+func changesTouch(changes []model.Change, table string) bool {
+	for _, change := range changes {
+		if change.Table == table {
+			return true
+		}
+	}
+	return false
+}
+
+// clampCursor keeps the data cursor inside the active table after rows were removed
+func (m *AppModel) clampCursor() {
+	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+		return
+	}
+
+	rowCount := len(m.tableData[m.tables[m.activeTableIdx]])
+	if m.cursorRow >= rowCount {
+		m.cursorRow = max(0, rowCount-1)
+	}
+	if m.mainScroll > m.cursorRow {
+		m.mainScroll = m.cursorRow
+	}
+}
+
+// changeMarks maps row indices of a table's data to the highlight of each changed column
+func (m *AppModel) changeMarks(table string) map[int]map[string]ui.CellMark {
+	marks := make(map[int]map[string]ui.CellMark)
+	changes := m.pendingChanges[table]
+	if len(changes) == 0 {
+		return marks
+	}
+
+	metadata := m.tableMetadata[table]
+	for i, row := range m.tableData[table] {
+		for _, change := range changes {
+			if !change.Row.Same(row) {
+				continue
+			}
+			if marks[i] == nil {
+				marks[i] = make(map[string]ui.CellMark)
+			}
+
+			switch change.Kind {
+			case model.ChangeUpdate:
+				marks[i][change.Column] = ui.MarkModified
+			case model.ChangeInsert, model.ChangeDelete:
+				mark := ui.MarkInserted
+				if change.Kind == model.ChangeDelete {
+					mark = ui.MarkDeleted
+				}
+				for _, col := range metadata {
+					marks[i][col.Name] = mark
+				}
+			}
+		}
+	}
+	return marks
+}
+
+// Handle keys when the change review modal is open
+func (m *AppModel) handleReviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showReview = false
+		return m, nil
+	case "c", "enter":
+		m.showReview = false
+		return m, m.commitChanges()
+	case "x":
+		if m.committing {
+			m.statusMessage = "Wait for the commit to finish"
+			return m, nil
+		}
+		m.discardChanges()
+		m.showReview = false
+		return m, nil
+	case "up", "k":
+		if m.reviewScroll > 0 {
+			m.reviewScroll--
+		}
+		return m, nil
+	case "down", "j":
+		if m.reviewScroll < m.pendingChangeCount()-1 {
+			m.reviewScroll++
+		}
+		return m, nil
+	}
+
+	return m, nil
 }
 
 // New function to handle setting cell to NULL
@@ -1041,6 +3416,9 @@ func (m *AppModel) setCellToNull() tea.Model {
 	if m.mode != model.DataMode || m.focusLeft {
 		return m
 	}
+	if m.writeBlocked() {
+		return m
+	}
 
 	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
 		return m
@@ -1064,21 +3442,57 @@ func (m *AppModel) setCellToNull() tea.Model {
 		colName := colMeta.Name
 		row := data[m.cursorRow]
 
-		// Set the value to nil directly in memory
+		if !m.stageChange(table, metadata, row, colName, nil) {
+			return m
+		}
+
+		// Set the value to nil in memory (committed later from the change set)
 		row[colName] = nil
 		data[m.cursorRow] = row
 		m.tableData[table] = data
-
-		// Optionally: Add feedback to the user (e.g., status message)
-		// m.statusMessage = fmt.Sprintf("Cell [%d, %d] set to NULL", m.cursorRow, m.cursorCol)
 	} else {
-		// Optionally: Add feedback if column is not nullable
-		// m.statusMessage = fmt.Sprintf("Column '%s' is not nullable", colMeta.Name)
+		m.statusMessage = fmt.Sprintf("Column '%s' is not nullable", colMeta.Name)
 	}
 
 	return m
 }
 
+// renderQueryView renders the query editor with the result of the last statement below it
+func (m AppModel) renderQueryView(styles ui.Styles, mainBoxWidth int) string {
+	status := m.queryStatus
+	if m.queryRunning {
+		status = ui.Spinner(m.spinnerFrame) + " Running..."
+	}
+
+	editor := ui.RenderQueryEditor(styles, mainBoxWidth, m.queryBuffer, m.queryEditing && !m.focusLeft, status)
+	if m.queryResult == nil || !m.queryResult.IsQuery {
+		return editor
+	}
+
+	// Give the result grid whatever height the editor leaves
+	resultStyles := styles
+	resultStyles.MainBoxStyle = styles.MainBoxStyle.Height(styles.MainBoxStyle.GetHeight() - ui.QueryEditorHeight)
+
+	return editor + ui.RenderTableData(
+		resultStyles,
+		mainBoxWidth,
+		"Result",
+		"",
+		nil,
+		m.queryResult.Columns,
+		m.queryResult.Rows,
+		nil,
+		nil,
+		0, 0, false,
+		m.cursorRow,
+		m.cursorCol,
+		m.focusLeft || m.queryEditing, // Hide the cursor while typing
+		false,
+		"",
+		m.mainScroll,
+	)
+}
+
 func (m AppModel) View() string {
 	// Check if connected to database
 	if !m.connected {
@@ -1107,26 +3521,65 @@ func (m AppModel) View() string {
 	}
 	mainBoxWidth := m.width - sidebarWidth - 4 // Account for borders
 
-	// Render table list sidebar
-	tablesToShow := m.getFilteredOrAllTables()
-	sidebarView := ui.RenderTableList(styles, tablesToShow, m.selectedIdx, m.activeTableIdx, m.sidebarScroll, m.filtering, m.filterBuffer, len(m.tables))
+	// Render the object tree sidebar
+	activeTable := ""
+	if m.activeTableIdx >= 0 && m.activeTableIdx < len(m.tables) {
+		activeTable = m.tables[m.activeTableIdx]
+	}
+	rows := m.sidebarRows()
+	items := make([]ui.SidebarItem, len(rows))
+	activeIdx, filterCount := -1, 0
+	for i, row := range rows {
+		switch {
+		case row.header != "":
+			items[i] = ui.SidebarItem{Name: row.header, Header: true}
+			continue
+		case row.object != nil:
+			items[i] = ui.SidebarItem{Name: row.object.Name}
+			if row.object == m.activeObject {
+				activeIdx = i
+			}
+		default:
+			items[i] = ui.SidebarItem{Name: row.table, Badge: ui.TableBadge(m.tableTypes[row.table]), Loading: m.tableLoading[row.table]}
+			if m.activeObject == nil && row.table == activeTable {
+				activeIdx = i
+			}
+		}
+		filterCount++
+	}
+	totalCount := len(m.tables) + len(m.objects)
+	sidebarView := ui.RenderTableList(styles, m.dbManager.CurrentDatabase(), items, m.selectedIdx, activeIdx, m.sidebarScroll, m.filtering, m.filterBuffer, totalCount, m.spinnerFrame)
 
 	// Render main content based on the selected table and mode
 	var mainContent string
 
-	if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
+	if m.mode == model.QueryMode {
+		mainContent = m.renderQueryView(styles, mainBoxWidth)
+	} else if m.activeObject != nil {
+		mainContent = ui.RenderObject(styles, *m.activeObject, m.mainScroll)
+	} else if m.activeTableIdx < 0 || m.activeTableIdx >= len(m.tables) {
 		mainContent = "No table selected"
 	} else {
 		activeTable := m.tables[m.activeTableIdx]
+		_, loaded := m.tableMetadata[activeTable]
 
-		if m.mode == model.DataMode {
+		if !loaded && m.tableLoading[activeTable] {
+			mainContent = ui.RenderLoading(mainBoxWidth, activeTable, m.spinnerFrame)
+		} else if m.mode == model.DataMode {
 			// Display table data
 			mainContent = ui.RenderTableData(
 				styles,
 				mainBoxWidth,
 				activeTable,
+				m.tableFilter[activeTable].String(),
+				m.tableSort[activeTable],
 				m.tableMetadata[activeTable],
 				m.tableData[activeTable],
+				m.changeMarks(activeTable),
+				m.searchHighlights(activeTable),
+				m.tableOffset[activeTable],
+				m.tableRowEstimate[activeTable]+int64(m.pendingInsertCount(activeTable)),
+				m.tableHasMore[activeTable],
 				m.cursorRow,
 				m.cursorCol,
 				m.focusLeft,
@@ -1136,10 +3589,21 @@ func (m AppModel) View() string {
 			)
 		} else if m.mode == model.StructureMode {
 			// Display table structure
-			mainContent = ui.RenderTableStructure(activeTable, m.tableMetadata[activeTable], m.mainScroll)
+			mainContent = ui.RenderTableStructure(styles, activeTable, m.tableMetadata[activeTable], m.mainScroll)
 		} else if m.mode == model.IndicesMode {
 			// Display indices information
 			mainContent = ui.RenderTableIndices(activeTable, m.tableIndices[activeTable], m.mainScroll)
+		} else if m.mode == model.RelationsMode {
+			// Display foreign keys in both directions
+			_, outgoing, incoming := m.activeRelations()
+			mainContent = ui.RenderTableRelations(styles, activeTable, outgoing, incoming, m.relationIdx, m.mainScroll)
+		} else if m.mode == model.DDLMode {
+			// Display the create statement, once fetched
+			if _, ok := m.tableDDL[activeTable]; !ok && m.tableLoading[activeTable] {
+				mainContent = ui.RenderLoading(mainBoxWidth, activeTable, m.spinnerFrame)
+			} else {
+				mainContent = ui.RenderTableDDL(styles, activeTable, m.tableDDL[activeTable], m.mainScroll)
+			}
 		}
 	}
 
@@ -1155,6 +3619,9 @@ func (m AppModel) View() string {
 		styles.DataTabStyle.Render("Data"),
 		styles.StructureTabStyle.Render("Structure"),
 		styles.IndicesTabStyle.Render("Indices"),
+		styles.RelationsTabStyle.Render("Relations"),
+		styles.DDLTabStyle.Render("DDL"),
+		styles.QueryTabStyle.Render("Query"),
 	)
 
 	buttonSection := buttonBar.Render(buttons)
@@ -1169,9 +3636,20 @@ func (m AppModel) View() string {
 	doc.WriteString(layout)
 
 	// Add status bar
-	filterCount := len(tablesToShow)
-	totalCount := len(m.tables)
-	statusBar := ui.RenderStatusBar(styles, m.width, m.filtering, filterCount, totalCount)
+	statusMessage := m.statusMessage
+	if m.dataSearching {
+		statusMessage = "Search: " + m.dataSearch + "█"
+	} else if statusMessage == "" && m.dataSearch != "" && m.mode == model.DataMode && activeTable != "" {
+		statusMessage = m.searchStatus(activeTable)
+	}
+	if statusMessage == "" {
+		if count := m.pendingChangeCount(); count > 0 {
+			statusMessage = fmt.Sprintf("%d pending change(s), Ctrl+S to review", count)
+		} else if m.dbConfig.ReadOnly {
+			statusMessage = "Read-only connection"
+		}
+	}
+	statusBar := ui.RenderStatusBar(styles, m.width, m.filtering, filterCount, totalCount, statusMessage)
 	doc.WriteString("\n" + statusBar)
 
 	// Add help text if enabled
@@ -1182,9 +3660,9 @@ func (m AppModel) View() string {
 
 		doc.WriteString("\n")
 		// Include filtering in help text
-		doc.WriteString(helpStyle.Render("Navigation: ↑/↓/←/→ or j/k/h/l | Edit: e/Enter | Modal Edit: Ctrl+E | Set Null: Ctrl+N"))
+		doc.WriteString(helpStyle.Render("Navigation: ↑/↓/←/→ or j/k/h/l | Edit: e/Enter | Modal Edit: Ctrl+E | Set Null: Ctrl+N | Add Row: a | Delete Row: Del/Ctrl+D | Review Changes: Ctrl+S"))
 		doc.WriteString("\n")
-		doc.WriteString(helpStyle.Render("Scroll: PgUp/PgDn/Home/End | Switch View: d/s/i | Filter Tables: / | Toggle Help: ? | Quit: q/Ctrl+C"))
+		doc.WriteString(helpStyle.Render("Scroll: PgUp/PgDn/Home/End | Switch View: d/s/i/r/c/x | Filter Rows: w (SQL) / F (column op value) | Sort: o (add column: O) | Search: / (next/previous: n/N) | Export: E | Follow Foreign Key: f | Back: b | Clear Search, then Row Filter: Esc | Run Query: Ctrl+R | Cancel Query: Esc | Filter Tables: / | Switch Database: D | Toggle Help: ? | Quit: q/Ctrl+C"))
 		if m.editing {
 			doc.WriteString("\n")
 			doc.WriteString(helpStyle.Render("Editing: Type to modify | Submit: Enter | Cancel: Esc"))
@@ -1193,31 +3671,95 @@ func (m AppModel) View() string {
 
 	// Render the modal if active
 	if m.showEditModal {
-		modalView := ui.RenderEditModal(styles, m.width, m.height, m.editingField, m.editBuffer)
+		modalView := ui.RenderEditModal(styles, m.width, m.height, m.editingField, m.editBuffer, m.statusMessage)
 		// Place the modal centered on top of the existing layout
 		// We need to join the layout and modal correctly. Lipgloss Place is good for this.
 		finalView := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalView, lipgloss.WithWhitespaceChars(" "))
 		return finalView // Return the view with the modal overlay
 	}
 
+	if m.showReview {
+		reviewView := ui.RenderChangeReview(styles, m.width, m.height, m.pendingStatements(), m.reviewScroll)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, reviewView, lipgloss.WithWhitespaceChars(" "))
+	}
+
+	if m.rowFilterPrompt != "" {
+		title := fmt.Sprintf("Filter rows of %s (%s)", m.tables[m.activeTableIdx], m.rowFilterPrompt)
+		help := "Enter: Apply (empty clears) | Esc: Cancel"
+		if m.statusMessage != "" {
+			help = m.statusMessage
+		}
+		promptView := ui.RenderPrompt(styles, m.width, m.height, title, m.rowFilterBuffer, help)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, promptView, lipgloss.WithWhitespaceChars(" "))
+	}
+
+	if m.exportPrompt {
+		title := fmt.Sprintf("Export %s to file", m.exportSource())
+		help := "Enter: Export as .csv, .tsv, .json or .ndjson | Esc: Cancel"
+		if m.statusMessage != "" {
+			help = m.statusMessage
+		}
+		promptView := ui.RenderPrompt(styles, m.width, m.height, title, m.exportBuffer, help)
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, promptView, lipgloss.WithWhitespaceChars(" "))
+	}
+
+	if m.showDatabasePicker {
+		pickerView := ui.RenderDatabasePicker(styles, m.width, m.height, m.databases, m.databaseIdx, m.dbManager.CurrentDatabase())
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, pickerView, lipgloss.WithWhitespaceChars(" "))
+	}
+
 	return doc.String()
 }
 
 func main() {
-	// Load configuration
-	dbConfig := config.LoadConfig()
+	profileName := flag.String("profile", "", "connection profile from "+config.DefaultPath())
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [--profile name] [connection-url | dsn | sqlite-file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Load configuration; an argument names the connection directly, either as a
+	// URL or DSN, or as the path of a SQLite database file
+	var profile *config.DBConfig
+	if flag.NArg() == 0 || *profileName != "" {
+		var err error
+		profile, err = selectProfile(*profileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	dbConfig, err := config.LoadConfig(profile)
+	if err == nil && flag.NArg() > 0 {
+		if target := flag.Arg(0); config.IsConnectionString(target) {
+			err = dbConfig.ApplyURL(target)
+			dbConfig.Normalize()
+		} else {
+			dbConfig.Driver = "sqlite"
+			dbConfig.Database = target
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	fmt.Printf("Connecting to MySQL database at %s:%d with user %s and database %s\n",
-		dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Database)
+	fmt.Printf("Connecting to %s\n", dbConfig.Summary())
 
 	// Initialize the model with database connection
-	m, err := NewAppModel()
+	m, err := NewAppModel(dbConfig)
 	if err != nil {
 		fmt.Printf("Error connecting to database: %v\n", err)
-		fmt.Println("Falling back to sample data...")
+		fmt.Println("Falling back to the demo database...")
 
-		// Fallback to sample data if database connection fails
-		m = NewAppModelWithSampleData()
+		// Fallback to an in-memory demo database if the connection fails
+		m, err = NewDemoAppModel(dbConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// Make sure to clean up on exit
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRemoveRow(t *testing.T) {
+	a, b, c := model.RowData{"id": 1}, model.RowData{"id": 2}, model.RowData{"id": 3}
+	copyOfB := model.RowData{"id": 2}
+
+	tests := []struct {
+		name string
+		data []model.RowData
+		row  model.RowData
+		want []model.RowData
+	}{
+		{"middle row", []model.RowData{a, b, c}, b, []model.RowData{a, c}},
+		{"last row", []model.RowData{a, b, c}, c, []model.RowData{a, b}},
+		{"equal values are another row", []model.RowData{a, b, c}, copyOfB, []model.RowData{a, b, c}},
+		{"empty", nil, a, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeRow(tt.data, tt.row); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removeRow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveRowChanges(t *testing.T) {
+	row, other := model.RowData{"id": 1}, model.RowData{"id": 2}
+	edit := model.Change{Kind: model.ChangeUpdate, Table: "users", Column: "name", Row: row}
+	insert := model.Change{Kind: model.ChangeInsert, Table: "users", Row: other}
+
+	tests := []struct {
+		name    string
+		changes []model.Change
+		row     model.RowData
+		want    []model.Change
+	}{
+		{"drops the row's changes", []model.Change{edit, insert, edit}, row, []model.Change{insert}},
+		{"keeps other rows", []model.Change{edit}, other, []model.Change{edit}},
+		{"nothing left", []model.Change{insert}, other, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeRowChanges(tt.changes, tt.row); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removeRowChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesTouch(t *testing.T) {
+	changes := []model.Change{{Kind: model.ChangeUpdate, Table: "users"}, {Kind: model.ChangeDelete, Table: "orders"}}
+
+	tests := []struct {
+		table string
+		want  bool
+	}{
+		{"users", true},
+		{"orders", true},
+		{"products", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if got := changesTouch(changes, tt.table); got != tt.want {
+				t.Errorf("changesTouch(%q) = %v, want %v", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlayChanges(t *testing.T) {
+	inserted := model.RowData{"id": nil, "name": "new"}
+	changes := func() []model.Change {
+		return []model.Change{
+			{Kind: model.ChangeUpdate, Table: "users", Column: "name", NewValue: "bob", Key: model.RowData{"id": int64(2)}},
+			{Kind: model.ChangeDelete, Table: "users", Key: model.RowData{"id": int64(3)}},
+			{Kind: model.ChangeInsert, Table: "users", Row: inserted},
+		}
+	}
+	page := func() []model.RowData {
+		return []model.RowData{{"id": int64(2), "name": "ann"}, {"id": int64(3), "name": "cy"}}
+	}
+
+	tests := []struct {
+		name    string
+		hasMore bool
+		want    []model.RowData
+	}{
+		{"last page shows inserts", false, []model.RowData{{"id": int64(2), "name": "bob"}, {"id": int64(3), "name": "cy"}, inserted}},
+		{"earlier page", true, []model.RowData{{"id": int64(2), "name": "bob"}, {"id": int64(3), "name": "cy"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &AppModel{
+				tableHasMore:   map[string]bool{"users": tt.hasMore},
+				pendingChanges: map[string][]model.Change{"users": changes()},
+			}
+			data := m.overlayChanges("users", page())
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Fatalf("overlayChanges() = %v, want %v", data, tt.want)
+			}
+
+			// Changes point at the fetched rows, so that committing or discarding finds them
+			staged := m.pendingChanges["users"]
+			if !staged[0].Row.Same(data[0]) || !staged[1].Row.Same(data[1]) {
+				t.Error("overlayChanges() did not attach the changes to the fetched rows")
+			}
+		})
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	row := model.RowData{"a": int64(1), "b": "x", "c": nil}
+
+	tests := []struct {
+		name string
+		key  model.RowData
+		want bool
+	}{
+		{"single column", model.RowData{"a": int64(1)}, true},
+		{"composite", model.RowData{"a": int64(1), "b": "x"}, true},
+		{"different value", model.RowData{"a": int64(2)}, false},
+		{"different type", model.RowData{"a": 1}, false},
+		{"NULL", model.RowData{"c": nil}, true},
+		{"missing column", model.RowData{"d": int64(1)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyMatches(tt.key, row); got != tt.want {
+				t.Errorf("keyMatches(%v) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleLoadsAreDropped(t *testing.T) {
+	failed := errors.New("boom")
+
+	tests := []struct {
+		name        string
+		msg         tea.Msg
+		wantApplied bool
+	}{
+		{"current table load", tableLoadedMsg{table: "users", load: 2, err: failed}, true},
+		{"replaced table load", tableLoadedMsg{table: "users", load: 1, err: failed}, false},
+		{"current page", pageLoadedMsg{table: "users", load: 2, err: failed}, true},
+		{"replaced page", pageLoadedMsg{table: "users", load: 1, err: failed}, false},
+		{"current DDL", ddlLoadedMsg{table: "users", load: 2, err: failed}, true},
+		{"replaced DDL", ddlLoadedMsg{table: "users", load: 1, err: failed}, false},
+		{"other table", pageLoadedMsg{table: "orders", load: 2, err: failed}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelled := false
+			m := &AppModel{
+				tableLoading: map[string]bool{"users": true},
+				loadCancels:  map[string]context.CancelFunc{"users": func() { cancelled = true }},
+				loadIDs:      map[string]int{"users": 2},
+			}
+			m.Update(tt.msg)
+
+			// A replaced load must leave the one in flight, and its cancel function, alone
+			if m.tableLoading["users"] == tt.wantApplied || cancelled != tt.wantApplied {
+				t.Errorf("loading = %v, cancelled = %v, want the load applied = %v", m.tableLoading["users"], cancelled, tt.wantApplied)
+			}
+			if (m.statusMessage != "") != tt.wantApplied {
+				t.Errorf("status = %q, want the error shown = %v", m.statusMessage, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestAdoptDatabaseDropsOldResults(t *testing.T) {
+	mm, err := NewDemoAppModel(config.DBConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mm
+	defer m.dbManager.Close()
+
+	ctx := context.Background()
+	next, err := m.dbManager.SwitchDatabase(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := next.GetTables(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := m.generation
+	m.queryResult = &model.QueryResult{IsQuery: true}
+	m.queryStatus = "1 row(s)"
+	m.adoptDatabase(next, tables, nil)
+	if m.queryResult != nil || m.queryStatus != "" {
+		t.Errorf("adoptDatabase() kept the query result %v, %q", m.queryResult, m.queryStatus)
+	}
+
+	tests := []struct {
+		name        string
+		generation  int
+		wantApplied bool
+	}{
+		{"result from the old database", old, false},
+		{"result from the new database", m.generation, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.queryResult = nil
+			m.Update(queryResultMsg{generation: tt.generation, result: &model.QueryResult{IsQuery: true}})
+			if (m.queryResult != nil) != tt.wantApplied {
+				t.Errorf("queryResult = %v, want applied = %v", m.queryResult, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestSearchMatches(t *testing.T) {
+	m := &AppModel{
+		tableMetadata: map[string][]model.ColumnMetadata{"users": {{Name: "id"}, {Name: "name"}, {Name: "email"}}},
+		tableData: map[string][]model.RowData{"users": {
+			{"id": int64(1), "name": "Ann", "email": "ann@example.com"},
+			{"id": int64(12), "name": "Bob", "email": nil},
+		}},
+	}
+
+	tests := []struct {
+		search string
+		want   []searchMatch
+	}{
+		{"", nil},
+		{"ann", []searchMatch{{row: 0, col: 1, positions: []int{0, 1, 2}}, {row: 0, col: 2, positions: []int{0, 1, 2}}}},
+		{"12", []searchMatch{{row: 1, col: 0, positions: []int{0, 1}}}},
+		{"bb", []searchMatch{{row: 1, col: 1, positions: []int{0, 2}}}},
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			m.dataSearch = tt.search
+			if got := m.searchMatches("users"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchMatches(%q) = %+v, want %+v", tt.search, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,7 @@
 #!/bin/bash
 
-# Run the application with explicit environment variables
-DB_HOST="127.0.0.1" \
-    DB_PORT="3306" \
-    DB_USER="root" \
-    DB_PASSWORD="root" \
-    DB_NAME="eedb" \
-    go run main.go
+# Run the application from source. Connection settings come from a profile in the
+# config file (--profile name), a connection URL argument, or the DB_URL and DB_*
+# variables of the calling environment, e.g.
+#   DB_USER=app DB_PASSWORD=... DB_NAME=shop ./run.sh
+go run . "$@"
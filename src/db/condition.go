@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+// conditionOperators are the comparisons ParseCondition understands, longest first
+// so that "<=" is not read as "<"
+var conditionOperators = []string{
+	"IS NOT NULL", "IS NULL", "NOT LIKE", "LIKE", "<=", ">=", "<>", "!=", "=", "<", ">",
+}
+
+// ParseCondition turns a simple "column op value" condition, such as
+// `age >= 30`, `name LIKE %son` or `deleted_at IS NULL`, into a SQL condition on the
+// quoted column with ? marking the value. The column must be one of columns; the
+// value may be wrapped in single or double quotes.
+func ParseCondition(dialect Dialect, columns []model.ColumnMetadata, input string) (string, []interface{}, error) {
+	input = strings.TrimSpace(input)
+
+	// The column is a bare word or a quoted name
+	var name string
+	if input != "" && strings.ContainsRune("\"`", rune(input[0])) {
+		end := strings.IndexByte(input[1:], input[0])
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated column name in %q", input)
+		}
+		name, input = input[1:end+1], input[end+2:]
+	} else {
+		end := strings.IndexFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$'
+		})
+		if end < 0 {
+			end = len(input)
+		}
+		name, input = input[:end], input[end:]
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("expected a condition like: column = value")
+	}
+
+	column := ""
+	for _, col := range columns {
+		if strings.EqualFold(col.Name, name) {
+			column = col.Name
+			break
+		}
+	}
+	if column == "" {
+		return "", nil, fmt.Errorf("unknown column %s", name)
+	}
+
+	input = strings.TrimSpace(input)
+	for _, op := range conditionOperators {
+		if len(input) < len(op) || !strings.EqualFold(input[:len(op)], op) {
+			continue
+		}
+		value := strings.TrimSpace(input[len(op):])
+
+		// Word operators must end at a word boundary
+		if unicode.IsLetter(rune(op[len(op)-1])) && len(input) > len(op) && input[len(op)] != ' ' {
+			continue
+		}
+
+		if strings.HasSuffix(op, "NULL") {
+			if value != "" {
+				return "", nil, fmt.Errorf("%s takes no value", op)
+			}
+			return dialect.QuoteIdentifier(column) + " " + op, nil, nil
+		}
+
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		return dialect.QuoteIdentifier(column) + " " + op + " ?", []interface{}{value}, nil
+	}
+
+	return "", nil, fmt.Errorf("expected one of =, !=, <, <=, >, >=, LIKE, NOT LIKE, IS NULL or IS NOT NULL after %s", column)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+func TestParseCondition(t *testing.T) {
+	columns := []model.ColumnMetadata{
+		{Name: "age", Type: "int"},
+		{Name: "name", Type: "varchar(255)"},
+		{Name: "deleted_at", Type: "datetime"},
+		{Name: "first name", Type: "text"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		input    string
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{"comparison", MySQL{}, "age >= 30", "`age` >= ?", []interface{}{"30"}, false},
+		{"no spaces", MySQL{}, "age<>30", "`age` <> ?", []interface{}{"30"}, false},
+		{"column name case", MySQL{}, "AGE != 30", "`age` != ?", []interface{}{"30"}, false},
+		{"like", MySQL{}, "name like %son", "`name` LIKE ?", []interface{}{"%son"}, false},
+		{"not like on postgres", Postgres{}, "name NOT LIKE 'a%'", `"name" NOT LIKE ?`, []interface{}{"a%"}, false},
+		{"double quoted value", MySQL{}, `name = "Ann Lee"`, "`name` = ?", []interface{}{"Ann Lee"}, false},
+		{"empty quoted value", MySQL{}, "name = ''", "`name` = ?", []interface{}{""}, false},
+		{"unbalanced quote is kept", MySQL{}, "name = 'Ann", "`name` = ?", []interface{}{"'Ann"}, false},
+		{"quoted column", SQLite{}, "`first name` = Ann", `"first name" = ?`, []interface{}{"Ann"}, false},
+		{"is null", MySQL{}, "deleted_at IS NULL", "`deleted_at` IS NULL", nil, false},
+		{"is not null", Postgres{}, "deleted_at is not null", `"deleted_at" IS NOT NULL`, nil, false},
+		{"empty", MySQL{}, "  ", "", nil, true},
+		{"unknown column", MySQL{}, "email = x", "", nil, true},
+		{"unterminated column", MySQL{}, "`first name = Ann", "", nil, true},
+		{"unknown operator", MySQL{}, "age ~ 30", "", nil, true},
+		{"operator run into a word", MySQL{}, "name LIKEx", "", nil, true},
+		{"null with a value", MySQL{}, "deleted_at IS NULL 5", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := ParseCondition(tt.dialect, columns, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCondition(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if condition != tt.wantSQL {
+				t.Errorf("ParseCondition(%q) = %q, want %q", tt.input, condition, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ParseCondition(%q) args = %#v, want %#v", tt.input, args, tt.wantArgs)
+			}
+		})
+	}
+}
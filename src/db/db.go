@@ -1,231 +1,766 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/md-salehzadeh/dbun/src/config"
 	"github.com/md-salehzadeh/dbun/src/model"
 
-	_ "github.com/go-sql-driver/mysql"
+	"golang.org/x/crypto/ssh"
 )
 
+// killTimeout bounds how long we wait for the server to acknowledge a KILL QUERY
+const killTimeout = 5 * time.Second
+
 // Manager handles database operations
 type Manager struct {
-	db     *sql.DB
-	config config.DBConfig
+	db      *sql.DB
+	tunnel  *sshTunnel // SSH connection the database is reached through, if any
+	config  config.DBConfig
+	dialect Dialect
 }
 
-// NewManager creates a new database manager
+// NewManager creates a new database manager for the configured driver
 func NewManager(config config.DBConfig) (*Manager, error) {
-	db, err := initDB(config)
+	dialect, err := DialectFor(config.Driver)
 	if err != nil {
 		return nil, err
 	}
 
+	db, client, err := initDB(dialect, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var tunnel *sshTunnel
+	if client != nil {
+		tunnel = newSSHTunnel(client)
+	}
+
 	return &Manager{
-		db:     db,
-		config: config,
+		db:      db,
+		tunnel:  tunnel,
+		config:  config,
+		dialect: dialect,
 	}, nil
 }
 
-// Close closes the database connection
+// Dialect returns the SQL dialect of the connected server
+func (m *Manager) Dialect() Dialect {
+	return m.dialect
+}
+
+// Close closes the database connection, and the SSH tunnel unless another manager
+// still uses it
 func (m *Manager) Close() error {
+	var err error
 	if m.db != nil {
-		return m.db.Close()
+		err = m.db.Close()
 	}
-	return nil
+	m.tunnel.release()
+	m.tunnel = nil
+	return err
 }
 
-// Initialize the database connection
-func initDB(config config.DBConfig) (*sql.DB, error) {
-	// Format DSN (Data Source Name) with UTF-8 character set parameters
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci",
-		config.User, config.Password, config.Host, config.Port, config.Database)
+// Initialize the database connection, through an SSH tunnel when one is configured
+func initDB(dialect Dialect, config config.DBConfig) (*sql.DB, *ssh.Client, error) {
+	ctx, cancel := WithTimeout(context.Background(), config.QueryTimeout)
+	defer cancel()
 
-	// Open connection
-	db, err := sql.Open("mysql", dsn)
+	var tunnel *ssh.Client
+	var dial DialFunc
+	if config.SSH.Host != "" {
+		var err error
+		tunnel, err = openSSHTunnel(ctx, config.SSH)
+		if err != nil {
+			return nil, nil, err
+		}
+		dial = tunnel.DialContext
+	}
+
+	db, err := openDB(ctx, dialect, config, dial)
+	if err != nil {
+		if tunnel != nil {
+			tunnel.Close()
+		}
+		return nil, nil, err
+	}
+
+	return db, tunnel, nil
+}
+
+// openDB opens the connection pool and checks that the server is reachable
+func openDB(ctx context.Context, dialect Dialect, config config.DBConfig, dial DialFunc) (*sql.DB, error) {
+	connector, err := dialect.Connector(config, dial)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to database: %v", err)
 	}
 
+	// Open connection
+	db := sql.OpenDB(connector)
+
 	// Test the connection
-	err = db.Ping()
-	if err != nil {
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("error pinging database: %v", err)
 	}
 
-	// Set connection parameters for proper UTF-8 handling
-	_, err = db.Exec("SET NAMES utf8mb4")
-	if err != nil {
-		return nil, fmt.Errorf("error setting character set: %v", err)
+	if err := dialect.Init(ctx, db); err != nil {
+		db.Close()
+		return nil, err
 	}
 
 	return db, nil
 }
 
-// GetTableNames fetches all table names from the database
-func (m *Manager) GetTableNames() ([]string, error) {
-	query := "SHOW TABLES"
-	rows, err := m.db.Query(query)
+// WithTimeout derives a cancellable context bounded by timeout; zero means no limit
+func WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// Config returns the settings the manager is connected with
+func (m *Manager) Config() config.DBConfig {
+	return m.config
+}
+
+// GetDatabaseNames fetches the databases (schemas for PostgreSQL) on the server
+func (m *Manager) GetDatabaseNames(ctx context.Context) ([]string, error) {
+	return m.dialect.Databases(ctx, m.db)
+}
+
+// CurrentDatabase names the database or schema being browsed
+func (m *Manager) CurrentDatabase() string {
+	return m.dialect.CurrentDatabase(m.config)
+}
+
+// SwitchDatabase connects to another database on the same server. The new manager
+// shares the SSH tunnel, which stays open until both managers are closed, so either
+// one can be dropped.
+func (m *Manager) SwitchDatabase(ctx context.Context, name string) (*Manager, error) {
+	cfg, err := m.dialect.SwitchDatabase(m.config, name)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching tables: %v", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	var tables []string
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("error scanning table name: %v", err)
-		}
-		tables = append(tables, tableName)
+	var dial DialFunc
+	if m.tunnel != nil {
+		dial = m.tunnel.client.DialContext
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating tables: %v", err)
+	db, err := openDB(ctx, m.dialect, cfg, dial)
+	if err != nil {
+		return nil, err
 	}
 
-	return tables, nil
+	next := &Manager{
+		db:      db,
+		tunnel:  m.tunnel.acquire(),
+		config:  cfg,
+		dialect: m.dialect,
+	}
+
+	return next, nil
+}
+
+// GetTables fetches all tables and views of the database with their types
+func (m *Manager) GetTables(ctx context.Context) ([]model.TableInfo, error) {
+	return m.dialect.Tables(ctx, m.db)
+}
+
+// GetObjects fetches the procedures, functions, triggers and events of the database
+func (m *Manager) GetObjects(ctx context.Context) ([]model.SchemaObject, error) {
+	return m.dialect.Objects(ctx, m.db)
 }
 
 // GetTableMetadata fetches column metadata for a specific table
-func (m *Manager) GetTableMetadata(tableName string) ([]model.ColumnMetadata, error) {
-	query := fmt.Sprintf("DESCRIBE %s", tableName)
-	rows, err := m.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("error fetching table metadata: %v", err)
+func (m *Manager) GetTableMetadata(ctx context.Context, tableName string) ([]model.ColumnMetadata, error) {
+	return m.dialect.Columns(ctx, m.db, tableName)
+}
+
+// GetTableIndices fetches indices for a specific table
+func (m *Manager) GetTableIndices(ctx context.Context, tableName string) ([]model.IndexMetadata, error) {
+	return m.dialect.Indices(ctx, m.db, tableName)
+}
+
+// GetForeignKeys fetches the foreign keys defined on a table and those referencing it
+func (m *Manager) GetForeignKeys(ctx context.Context, tableName string) ([]model.ForeignKey, error) {
+	return m.dialect.ForeignKeys(ctx, m.db, tableName)
+}
+
+// GetCreateStatement fetches the DDL of a table or view
+func (m *Manager) GetCreateStatement(ctx context.Context, tableName string) (string, error) {
+	return m.dialect.CreateStatement(ctx, m.db, tableName)
+}
+
+// KeyColumns returns the columns that uniquely identify a row, preferring the
+// primary key and falling back to a single non-nullable unique column
+func KeyColumns(metadata []model.ColumnMetadata) []string {
+	var keys []string
+	for _, col := range metadata {
+		if col.Key == "PRI" {
+			keys = append(keys, col.Name)
+		}
+	}
+	if len(keys) > 0 {
+		return keys
 	}
-	defer rows.Close()
 
-	var columns []model.ColumnMetadata
-	for rows.Next() {
-		var field, dataType, null, key, defaultVal, extra sql.NullString
-		if err := rows.Scan(&field, &dataType, &null, &key, &defaultVal, &extra); err != nil {
-			return nil, fmt.Errorf("error scanning column metadata: %v", err)
+	for _, col := range metadata {
+		if col.Key == "UNI" && !col.Nullable {
+			return []string{col.Name}
 		}
+	}
+
+	return nil
+}
+
+// RowKey extracts the key column values of a row, failing if the table has no
+// usable key or one of the key values is missing
+func RowKey(tableName string, metadata []model.ColumnMetadata, row model.RowData) (model.RowData, error) {
+	keys := KeyColumns(metadata)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("table %s has no primary or unique key, refusing to modify it", tableName)
+	}
 
-		column := model.ColumnMetadata{
-			Name:     field.String,
-			Type:     dataType.String,
-			Nullable: null.String == "YES",
-			Key:      key.String,
+	key := make(model.RowData, len(keys))
+	for _, col := range keys {
+		val, ok := row[col]
+		if !ok || val == nil {
+			return nil, fmt.Errorf("missing value for key column %s", col)
 		}
-		columns = append(columns, column)
+		key[col] = val
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating columns: %v", err)
+	return key, nil
+}
+
+// errReadOnly is returned by every write on a read-only connection
+var errReadOnly = errors.New("connection is read-only")
+
+// UpdateCell writes a new value for one column of the row identified by its key columns.
+// The row must hold the original (unedited) values so the WHERE clause matches.
+func (m *Manager) UpdateCell(ctx context.Context, tableName string, metadata []model.ColumnMetadata, row model.RowData, column string, value interface{}) (int64, error) {
+	if m.config.ReadOnly {
+		return 0, errReadOnly
+	}
+
+	key, err := RowKey(tableName, metadata, row)
+	if err != nil {
+		return 0, err
 	}
 
-	return columns, nil
+	return execChange(ctx, m.db, m.dialect, model.Change{
+		Kind:     model.ChangeUpdate,
+		Table:    tableName,
+		Key:      key,
+		Column:   column,
+		NewValue: value,
+	})
 }
 
-// GetTableIndices fetches indices for a specific table
-func (m *Manager) GetTableIndices(tableName string) ([]string, error) {
-	query := fmt.Sprintf("SHOW INDEX FROM %s", tableName)
-	rows, err := m.db.Query(query)
+// InsertRow inserts a new row. Columns with a nil value are left out so the
+// server applies their defaults (including auto-increment keys).
+func (m *Manager) InsertRow(ctx context.Context, tableName string, row model.RowData) (int64, error) {
+	if m.config.ReadOnly {
+		return 0, errReadOnly
+	}
+
+	return execChange(ctx, m.db, m.dialect, model.Change{
+		Kind:  model.ChangeInsert,
+		Table: tableName,
+		Row:   row,
+	})
+}
+
+// DeleteRow deletes the row identified by its key columns
+func (m *Manager) DeleteRow(ctx context.Context, tableName string, metadata []model.ColumnMetadata, row model.RowData) (int64, error) {
+	if m.config.ReadOnly {
+		return 0, errReadOnly
+	}
+
+	key, err := RowKey(tableName, metadata, row)
+	if err != nil {
+		return 0, err
+	}
+
+	return execChange(ctx, m.db, m.dialect, model.Change{
+		Kind:  model.ChangeDelete,
+		Table: tableName,
+		Key:   key,
+	})
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// execChange runs the statement for a single change and returns the affected row count
+func execChange(ctx context.Context, ex execer, dialect Dialect, change model.Change) (int64, error) {
+	query, args, err := BuildStatement(dialect, change)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching table indices: %v", err)
+		return 0, err
 	}
-	defer rows.Close()
 
-	// Get column names from result set
-	columns, err := rows.Columns()
+	result, err := ex.ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error getting index columns: %v", err)
+		return 0, fmt.Errorf("error executing %s on %s: %v", change.Kind, change.Table, err)
 	}
 
-	// Create a slice of interface{} to hold the values
-	values := make([]interface{}, len(columns))
-	scanArgs := make([]interface{}, len(columns))
-	for i := range values {
-		scanArgs[i] = &values[i]
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error reading affected rows: %v", err)
 	}
 
-	indexMap := make(map[string]bool) // Use map to avoid duplicates
+	return affected, nil
+}
+
+// ApplyChanges executes all staged changes inside a single transaction.
+// Either every statement succeeds and the transaction is committed, or nothing is applied.
+// Each UPDATE and DELETE must affect exactly the one row its key identifies; a key that
+// matches no row, or several, means the row changed underneath and rolls everything back.
+func (m *Manager) ApplyChanges(ctx context.Context, changes []model.Change) (int64, error) {
+	if m.config.ReadOnly {
+		return 0, errReadOnly
+	}
 
-	// For each row, scan all columns
-	for rows.Next() {
-		err := rows.Scan(scanArgs...)
+	var total int64
+	err := m.withKillableConn(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning index data: %v", err)
+			return fmt.Errorf("error starting transaction: %v", err)
 		}
 
-		// Extract the key name (usually in position 2)
-		keyNameIdx := 2 // Default position for key_name
+		for _, change := range changes {
+			affected, err := execChange(ctx, tx, m.dialect, change)
+			if err == nil && change.Kind != model.ChangeInsert && affected != 1 {
+				err = fmt.Errorf("%s on %s where %s affected %d rows instead of 1",
+					change.Kind, change.Table, model.RowFilter{Equal: change.Key}, affected)
+			}
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			total += affected
+		}
 
-		// Find the key_name column position for flexibility
-		for i, colName := range columns {
-			if strings.EqualFold(colName, "Key_name") {
-				keyNameIdx = i
-				break
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing transaction: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// BuildStatement generates the parameterized SQL statement for a staged change
+func BuildStatement(dialect Dialect, change model.Change) (string, []interface{}, error) {
+	switch change.Kind {
+	case model.ChangeUpdate:
+		if len(change.Key) == 0 {
+			return "", nil, fmt.Errorf("change on %s has no key values", change.Table)
+		}
+
+		where, args := keyConditions(dialect, change.Key, 2)
+		query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s",
+			dialect.QuoteIdentifier(change.Table), dialect.QuoteIdentifier(change.Column), dialect.Placeholder(1), where)
+		return query, append([]interface{}{change.NewValue}, args...), nil
+
+	case model.ChangeInsert:
+		cols := make([]string, 0, len(change.Row))
+		for col, val := range change.Row {
+			if val != nil {
+				cols = append(cols, col)
 			}
 		}
+		sort.Strings(cols)
+
+		quoted := make([]string, len(cols))
+		placeholders := make([]string, len(cols))
+		args := make([]interface{}, len(cols))
+		for i, col := range cols {
+			quoted[i] = dialect.QuoteIdentifier(col)
+			placeholders[i] = dialect.Placeholder(i + 1)
+			args[i] = change.Row[col]
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			dialect.QuoteIdentifier(change.Table), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+		return query, args, nil
+
+	case model.ChangeDelete:
+		if len(change.Key) == 0 {
+			return "", nil, fmt.Errorf("change on %s has no key values", change.Table)
+		}
 
-		// Get the key name if it exists and is not null
-		if keyNameIdx < len(values) && values[keyNameIdx] != nil {
-			switch v := values[keyNameIdx].(type) {
-			case []byte:
-				indexMap[string(v)] = true
-			case string:
-				indexMap[v] = true
+		where, args := keyConditions(dialect, change.Key, 1)
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s", dialect.QuoteIdentifier(change.Table), where)
+		return query, args, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported change kind: %s", change.Kind)
+}
+
+// InlineStatement renders a parameterized statement with its arguments substituted,
+// for display purposes only (never execute the result). Both ? and $n markers are understood.
+func InlineStatement(query string, args []interface{}) string {
+	var sb strings.Builder
+	argIdx := 0
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		if ch == '?' && argIdx < len(args) {
+			sb.WriteString(formatLiteral(args[argIdx]))
+			argIdx++
+			continue
+		}
+
+		if ch == '$' {
+			end := i + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			if n, err := strconv.Atoi(query[i+1 : end]); err == nil && n >= 1 && n <= len(args) {
+				sb.WriteString(formatLiteral(args[n-1]))
+				i = end - 1
+				continue
 			}
 		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
+
+// keyConditions builds a WHERE clause matching every key column, in a stable column order.
+// Placeholders are numbered from first for dialects with positional markers.
+func keyConditions(dialect Dialect, key model.RowData, first int) (string, []interface{}) {
+	cols := make([]string, 0, len(key))
+	for col := range key {
+		cols = append(cols, col)
 	}
+	sort.Strings(cols)
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating indices: %v", err)
+	conditions := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		conditions[i] = fmt.Sprintf("%s = %s", dialect.QuoteIdentifier(col), dialect.Placeholder(first+i))
+		args[i] = key[col]
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// filterClause builds the WHERE clause of a row filter, or an empty string when the
+// filter is empty
+func filterClause(dialect Dialect, filter model.RowFilter) (string, []interface{}) {
+	if filter.IsEmpty() {
+		return "", nil
+	}
+
+	conditions, args := keyConditions(dialect, filter.Equal, 1)
+	if filter.Where != "" {
+		where := filter.Where
+		if len(filter.Args) > 0 {
+			where = bindPlaceholders(dialect, where, len(args)+1)
+			args = append(args, filter.Args...)
+		}
+		if conditions != "" {
+			conditions += " AND "
+		}
+		conditions += "(" + where + ")"
+	}
+	return " WHERE " + conditions, args
+}
+
+// orderClause builds the ORDER BY clause for the sort columns followed by the key
+// columns not already sorted on, or an empty string when there is neither
+func orderClause(dialect Dialect, columns []model.ColumnMetadata, sort []model.SortColumn) string {
+	var orderBy []string
+	sorted := make(map[string]bool, len(sort))
+	for _, s := range sort {
+		term := dialect.QuoteIdentifier(s.Column)
+		if s.Desc {
+			term += " DESC"
+		}
+		orderBy = append(orderBy, term)
+		sorted[s.Column] = true
+	}
+
+	for _, key := range KeyColumns(columns) {
+		if !sorted[key] {
+			orderBy = append(orderBy, dialect.QuoteIdentifier(key))
+		}
+	}
+
+	if len(orderBy) == 0 {
+		return ""
 	}
+	return " ORDER BY " + strings.Join(orderBy, ", ")
+}
+
+// bindPlaceholders replaces each ? outside quotes in a condition with the dialect's
+// marker for the next parameter, numbering from first
+func bindPlaceholders(dialect Dialect, condition string, first int) string {
+	var sb strings.Builder
+	var quote rune
+	n := first
+	for _, r := range condition {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			sb.WriteString(dialect.Placeholder(n))
+			n++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// formatLiteral formats a value as a SQL literal for display
+func formatLiteral(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return model.FormatValue(v)
+	default:
+		return "'" + strings.ReplaceAll(model.FormatValue(v), "'", "''") + "'"
+	}
+}
 
-	// Convert map keys to slice
-	indices := make([]string, 0, len(indexMap))
-	for key := range indexMap {
-		indices = append(indices, key)
+// EstimateRowCount returns the approximate number of rows in a table from
+// server statistics, which is cheap but may be stale
+func (m *Manager) EstimateRowCount(ctx context.Context, tableName string) (int64, error) {
+	return m.dialect.EstimateRowCount(ctx, m.db, tableName)
+}
+
+// CountRows returns the exact number of rows in a table that match the filter
+func (m *Manager) CountRows(ctx context.Context, tableName string, filter model.RowFilter) (int64, error) {
+	where, args := filterClause(m.dialect, filter)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", m.dialect.QuoteIdentifier(tableName), where)
+
+	var count int64
+	err := m.withKillableConn(ctx, func(conn *sql.Conn) error {
+		return conn.QueryRowContext(ctx, query, args...).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error counting rows: %v", err)
 	}
 
-	return indices, nil
+	return count, nil
 }
 
-// GetTableData fetches data for a specific table (limited to a reasonable number of rows)
-func (m *Manager) GetTableData(tableName string, limit int) ([]model.RowData, error) {
+// GetTableData fetches one page of the rows of a table that match the filter, starting at offset.
+// Rows are ordered by the sort columns, then by the key columns when the table has any,
+// so pages are stable.
+func (m *Manager) GetTableData(ctx context.Context, tableName string, filter model.RowFilter, sort []model.SortColumn, offset, limit int) ([]model.RowData, error) {
 	// Get columns first to handle the results properly
-	columns, err := m.GetTableMetadata(tableName)
+	columns, err := m.GetTableMetadata(ctx, tableName)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build query with column names with proper backtick escaping for MySQL
+	query, args := selectQuery(m.dialect, tableName, columns, filter, sort)
+	query = m.dialect.Paginate(query, offset, limit)
+
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+
+	var result []model.RowData
+	err = m.withKillableConn(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("error fetching data: %v", err)
+		}
+		defer rows.Close()
+
+		result, err = scanRows(rows, names)
+		return err
+	})
+
+	return result, err
+}
+
+// selectQuery builds the SELECT of the rows of a table that match the filter, in the
+// order GetTableData pages through them
+func selectQuery(dialect Dialect, tableName string, columns []model.ColumnMetadata, filter model.RowFilter, sort []model.SortColumn) (string, []interface{}) {
+	// Quote column names to handle reserved words and special characters
 	columnNames := make([]string, len(columns))
 	for i, col := range columns {
-		// Escape column names with backticks to handle reserved words and special characters
-		columnNames[i] = fmt.Sprintf("`%s`", col.Name)
+		columnNames[i] = dialect.QuoteIdentifier(col.Name)
+	}
+
+	where, args := filterClause(dialect, filter)
+	query := fmt.Sprintf("SELECT %s FROM %s%s%s", strings.Join(columnNames, ", "), dialect.QuoteIdentifier(tableName),
+		where, orderClause(dialect, columns, sort))
+	return query, args
+}
+
+// Query runs an arbitrary SQL statement. Statements that produce a result set
+// return their columns and rows; all others report the number of affected rows.
+// On a read-only connection only statements that return rows are accepted, and they
+// run in a read-only transaction so the server refuses any write they attempt.
+func (m *Manager) Query(ctx context.Context, query string) (*model.QueryResult, error) {
+	start := time.Now()
+	result := &model.QueryResult{IsQuery: returnsRows(query)}
+	if m.config.ReadOnly && !result.IsQuery {
+		return nil, errReadOnly
 	}
 
-	query := fmt.Sprintf("SELECT %s FROM `%s` LIMIT %d",
-		strings.Join(columnNames, ", "), tableName, limit)
+	err := m.withKillableConn(ctx, func(conn *sql.Conn) error {
+		if !result.IsQuery {
+			res, err := conn.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+			result.RowsAffected, _ = res.RowsAffected()
+			return nil
+		}
+
+		var q querier = conn
+		if m.config.ReadOnly {
+			// The leading keyword is only a guess: a data-modifying CTE or a function
+			// with side effects writes from a SELECT as well
+			tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				return fmt.Errorf("error starting transaction: %v", err)
+			}
+			defer tx.Rollback()
+			q = tx
+		}
+
+		rows, err := q.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
 
-	rows, err := m.db.Query(query)
+		colTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return fmt.Errorf("error getting column types: %v", err)
+		}
+
+		// Result sets may repeat a column name (e.g. joins), but RowData is keyed by name
+		seen := make(map[string]int)
+		names := make([]string, len(colTypes))
+		for i, colType := range colTypes {
+			name := colType.Name()
+			seen[name]++
+			if seen[name] > 1 {
+				name = fmt.Sprintf("%s (%d)", name, seen[name])
+			}
+			names[i] = name
+
+			nullable, _ := colType.Nullable()
+			result.Columns = append(result.Columns, model.ColumnMetadata{
+				Name:     name,
+				Type:     strings.ToLower(colType.DatabaseTypeName()),
+				Nullable: nullable,
+			})
+		}
+
+		result.RawRows, err = scanValues(rows, len(names))
+		result.Rows = rowData(result.RawRows, colTypes, names)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error fetching data: %v", err)
+		return nil, err
+	}
+	result.Elapsed = time.Since(start)
+
+	return result, nil
+}
+
+// withKillableConn runs fn on a dedicated connection. When ctx is cancelled or times
+// out while fn is running, the statement is also cancelled on the server, since not
+// every driver stops the server-side work when it abandons a connection.
+func (m *Manager) withKillableConn(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring connection: %v", err)
+	}
+	defer conn.Close()
+
+	connID, err := m.dialect.ConnectionID(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("error reading connection id: %v", err)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		// ctx is already done, so the kill needs a context of its own
+		killCtx, cancel := context.WithTimeout(context.Background(), killTimeout)
+		defer cancel()
+		m.dialect.CancelQuery(killCtx, m.db, connID)
+	})
+	defer stop()
+
+	return fn(conn)
+}
+
+// returnsRows guesses from the leading keyword whether a statement produces a result set
+func returnsRows(query string) bool {
+	fields := strings.Fields(strings.TrimLeft(query, "( \t\r\n"))
+	if len(fields) == 0 {
+		return false
 	}
-	defer rows.Close()
 
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "WITH", "VALUES", "TABLE", "PRAGMA":
+		return true
+	}
+	return false
+}
+
+// scanRows reads all rows of a result set into RowData maps keyed by the given column names,
+// converting driver byte slices into Go types based on the column's database type
+func scanRows(rows *sql.Rows, columnNames []string) ([]model.RowData, error) {
 	// Get column types to properly handle NULL values
 	colTypes, err := rows.ColumnTypes()
 	if err != nil {
 		return nil, fmt.Errorf("error getting column types: %v", err)
 	}
 
-	var result []model.RowData
+	raw, err := scanValues(rows, len(columnNames))
+	if err != nil {
+		return nil, err
+	}
 
-	// For each row
+	return rowData(raw, colTypes, columnNames), nil
+}
+
+// scanValues reads all rows of a result set as the driver returns their values
+func scanValues(rows *sql.Rows, numColumns int) ([][]interface{}, error) {
+	var result [][]interface{}
 	for rows.Next() {
 		// Create a slice of interface{} to hold the values
-		values := make([]interface{}, len(columnNames))
+		values := make([]interface{}, numColumns)
 		// Create a slice of pointers to the values
-		scanArgs := make([]interface{}, len(columnNames))
+		scanArgs := make([]interface{}, numColumns)
 		for i := range values {
 			scanArgs[i] = &values[i]
 		}
@@ -234,53 +769,7 @@ func (m *Manager) GetTableData(tableName string, limit int) ([]model.RowData, er
 		if err := rows.Scan(scanArgs...); err != nil {
 			return nil, fmt.Errorf("error scanning row: %v", err)
 		}
-
-		// Create a map to hold the row data
-		rowData := make(model.RowData)
-
-		// For each column in the row
-		for i, col := range columns {
-			colName := col.Name
-
-			// Handle different types
-			var v interface{}
-			val := values[i]
-
-			// Handle NULL values
-			if val == nil {
-				v = nil
-			} else {
-				// Handle different types based on the column type
-				switch colTypes[i].DatabaseTypeName() {
-				case "INT", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT":
-					switch val.(type) {
-					case []byte:
-						v, _ = model.ParseInt(string(val.([]byte)))
-					default:
-						v = val
-					}
-				case "DECIMAL", "FLOAT", "DOUBLE":
-					switch val.(type) {
-					case []byte:
-						v, _ = model.ParseFloat(string(val.([]byte)))
-					default:
-						v = val
-					}
-				default:
-					// For TEXT, VARCHAR, etc. convert []byte to string
-					switch val.(type) {
-					case []byte:
-						v = string(val.([]byte))
-					default:
-						v = val
-					}
-				}
-			}
-
-			rowData[colName] = v
-		}
-
-		result = append(result, rowData)
+		result = append(result, values)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -289,3 +778,37 @@ func (m *Manager) GetTableData(tableName string, limit int) ([]model.RowData, er
 
 	return result, nil
 }
+
+// rowData converts driver values into RowData maps keyed by the given column names
+func rowData(raw [][]interface{}, colTypes []*sql.ColumnType, columnNames []string) []model.RowData {
+	result := make([]model.RowData, len(raw))
+	for r, values := range raw {
+		row := make(model.RowData, len(columnNames))
+		for i, colName := range columnNames {
+			row[colName] = convertValue(colTypes[i].DatabaseTypeName(), values[i])
+		}
+		result[r] = row
+	}
+	return result
+}
+
+// convertValue turns a driver value into the Go type shown for the column's database
+// type: integers and floats are parsed, other byte slices become strings
+func convertValue(dbType string, val interface{}) interface{} {
+	b, ok := val.([]byte)
+	if !ok {
+		// NULL, or a value the driver already typed
+		return val
+	}
+
+	switch dbType {
+	case "INT", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "INT2", "INT4", "INT8":
+		v, _ := model.ParseInt(string(b))
+		return v
+	case "DECIMAL", "FLOAT", "DOUBLE", "NUMERIC", "FLOAT4", "FLOAT8":
+		v, _ := model.ParseFloat(string(b))
+		return v
+	}
+	// For TEXT, VARCHAR, etc. convert []byte to string
+	return string(b)
+}
@@ -0,0 +1,388 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+func TestBuildStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		change   model.Change
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:    "update on mysql",
+			dialect: MySQL{},
+			change: model.Change{Kind: model.ChangeUpdate, Table: "users", Column: "name",
+				NewValue: "bob", Key: model.RowData{"id": 7}},
+			wantSQL:  "UPDATE `users` SET `name` = ? WHERE `id` = ?",
+			wantArgs: []interface{}{"bob", 7},
+		},
+		{
+			name:    "update with composite key on postgres",
+			dialect: Postgres{},
+			change: model.Change{Kind: model.ChangeUpdate, Table: "order_items", Column: "qty",
+				NewValue: 3, Key: model.RowData{"product_id": 2, "order_id": 1}},
+			wantSQL:  `UPDATE "order_items" SET "qty" = $1 WHERE "order_id" = $2 AND "product_id" = $3`,
+			wantArgs: []interface{}{3, 1, 2},
+		},
+		{
+			name:    "update to NULL",
+			dialect: SQLite{},
+			change: model.Change{Kind: model.ChangeUpdate, Table: "users", Column: "email",
+				NewValue: nil, Key: model.RowData{"id": 1}},
+			wantSQL:  `UPDATE "users" SET "email" = ? WHERE "id" = ?`,
+			wantArgs: []interface{}{nil, 1},
+		},
+		{
+			name:    "update without key",
+			dialect: MySQL{},
+			change:  model.Change{Kind: model.ChangeUpdate, Table: "users", Column: "name", NewValue: "bob"},
+			wantErr: true,
+		},
+		{
+			name:    "insert skips NULL columns",
+			dialect: Postgres{},
+			change: model.Change{Kind: model.ChangeInsert, Table: "users",
+				Row: model.RowData{"name": "bob", "id": nil, "active": true}},
+			wantSQL:  `INSERT INTO "users" ("active", "name") VALUES ($1, $2)`,
+			wantArgs: []interface{}{true, "bob"},
+		},
+		{
+			name:     "delete",
+			dialect:  MySQL{},
+			change:   model.Change{Kind: model.ChangeDelete, Table: "users", Key: model.RowData{"id": 7}},
+			wantSQL:  "DELETE FROM `users` WHERE `id` = ?",
+			wantArgs: []interface{}{7},
+		},
+		{
+			name:    "delete without key",
+			dialect: Postgres{},
+			change:  model.Change{Kind: model.ChangeDelete, Table: "users"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown kind",
+			dialect: MySQL{},
+			change:  model.Change{Kind: "MERGE", Table: "users", Key: model.RowData{"id": 7}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := BuildStatement(tt.dialect, tt.change)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if query != tt.wantSQL {
+				t.Errorf("BuildStatement() query = %q, want %q", query, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("BuildStatement() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestInlineStatement(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{"question marks", "UPDATE t SET a = ? WHERE id = ?", []interface{}{"x", 5}, "UPDATE t SET a = 'x' WHERE id = 5"},
+		{"numbered markers", `UPDATE t SET a = $1 WHERE id = $2`, []interface{}{1.5, int64(9)}, "UPDATE t SET a = 1.5 WHERE id = 9"},
+		{"markers out of order", "SELECT $2, $1", []interface{}{"a", "b"}, "SELECT 'b', 'a'"},
+		{"two-digit marker", "SELECT $10", []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, "SELECT 10"},
+		{"quotes are escaped", "SET a = ?", []interface{}{"it's"}, "SET a = 'it''s'"},
+		{"NULL and booleans", "VALUES (?, ?, ?)", []interface{}{nil, true, false}, "VALUES (NULL, 1, 0)"},
+		{"missing arguments are kept", "WHERE a = ? AND b = ?", []interface{}{1}, "WHERE a = 1 AND b = ?"},
+		{"dollar without number", "SELECT '$' || $1", []interface{}{"x"}, "SELECT '$' || 'x'"},
+		{"marker past the arguments", "SELECT $3", []interface{}{1}, "SELECT $3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InlineStatement(tt.query, tt.args); got != tt.want {
+				t.Errorf("InlineStatement(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata []model.ColumnMetadata
+		want     []string
+	}{
+		{"primary key", []model.ColumnMetadata{{Name: "id", Key: "PRI"}, {Name: "email", Key: "UNI"}}, []string{"id"}},
+		{"composite primary key", []model.ColumnMetadata{{Name: "a", Key: "PRI"}, {Name: "b", Key: "PRI"}, {Name: "c"}}, []string{"a", "b"}},
+		{"unique column", []model.ColumnMetadata{{Name: "name"}, {Name: "email", Key: "UNI"}}, []string{"email"}},
+		{"nullable unique column", []model.ColumnMetadata{{Name: "email", Key: "UNI", Nullable: true}}, nil},
+		{"first unique column", []model.ColumnMetadata{{Name: "a", Key: "UNI"}, {Name: "b", Key: "UNI"}}, []string{"a"}},
+		{"no key", []model.ColumnMetadata{{Name: "a", Key: "MUL"}, {Name: "b"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeyColumns(tt.metadata); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		filter   model.RowFilter
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"empty", MySQL{}, model.RowFilter{}, "", nil},
+		{"one column", MySQL{}, model.RowFilter{Equal: model.RowData{"user_id": 3}},
+			" WHERE `user_id` = ?", []interface{}{3}},
+		{"columns in name order", Postgres{}, model.RowFilter{Equal: model.RowData{"b": "x", "a": 1}},
+			` WHERE "a" = $1 AND "b" = $2`, []interface{}{1, "x"}},
+		{"condition without arguments", MySQL{}, model.RowFilter{Where: "deleted_at IS NULL"},
+			" WHERE (deleted_at IS NULL)", []interface{}{}},
+		{"key and condition", Postgres{}, model.RowFilter{Equal: model.RowData{"id": 1}, Where: `"age" >= ? OR "age" < ?`, Args: []interface{}{"30", "10"}},
+			` WHERE "id" = $1 AND ("age" >= $2 OR "age" < $3)`, []interface{}{1, "30", "10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := filterClause(tt.dialect, tt.filter)
+			if where != tt.wantSQL {
+				t.Errorf("filterClause() = %q, want %q", where, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("filterClause() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBindPlaceholders(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		condition string
+		first     int
+		want      string
+	}{
+		{"mysql keeps question marks", MySQL{}, "a = ? AND b = ?", 1, "a = ? AND b = ?"},
+		{"postgres numbers from first", Postgres{}, "a = ? AND b = ?", 2, "a = $2 AND b = $3"},
+		{"single quotes", Postgres{}, "a = '?' AND b = ?", 1, "a = '?' AND b = $1"},
+		{"double quotes", Postgres{}, `"a?" = ?`, 1, `"a?" = $1`},
+		{"backticks", MySQL{}, "`a?` = ?", 1, "`a?` = ?"},
+		{"no markers", Postgres{}, "a IS NULL", 1, "a IS NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bindPlaceholders(tt.dialect, tt.condition, tt.first); got != tt.want {
+				t.Errorf("bindPlaceholders(%q, %d) = %q, want %q", tt.condition, tt.first, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	keyed := []model.ColumnMetadata{{Name: "id", Key: "PRI"}, {Name: "name"}, {Name: "age"}}
+	composite := []model.ColumnMetadata{{Name: "a", Key: "PRI"}, {Name: "b", Key: "PRI"}, {Name: "qty"}}
+	unkeyed := []model.ColumnMetadata{{Name: "name"}, {Name: "age"}}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		columns []model.ColumnMetadata
+		sort    []model.SortColumn
+		want    string
+	}{
+		{"key only", MySQL{}, keyed, nil, " ORDER BY `id`"},
+		{"sort then key", MySQL{}, keyed, []model.SortColumn{{Column: "name", Desc: true}}, " ORDER BY `name` DESC, `id`"},
+		{"several sort columns", Postgres{}, keyed, []model.SortColumn{{Column: "age"}, {Column: "name", Desc: true}},
+			` ORDER BY "age", "name" DESC, "id"`},
+		{"sorted key is not repeated", Postgres{}, keyed, []model.SortColumn{{Column: "id", Desc: true}}, ` ORDER BY "id" DESC`},
+		{"rest of a composite key", SQLite{}, composite, []model.SortColumn{{Column: "b"}}, ` ORDER BY "b", "a"`},
+		{"no key", MySQL{}, unkeyed, []model.SortColumn{{Column: "age"}}, " ORDER BY `age`"},
+		{"nothing to order by", MySQL{}, unkeyed, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderClause(tt.dialect, tt.columns, tt.sort); got != tt.want {
+				t.Errorf("orderClause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	columns := []model.ColumnMetadata{{Name: "id", Key: "PRI"}, {Name: "name"}}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		filter   model.RowFilter
+		sort     []model.SortColumn
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"all rows", MySQL{}, model.RowFilter{}, nil, "SELECT `id`, `name` FROM `users` ORDER BY `id`", nil},
+		{"filtered and sorted", Postgres{}, model.RowFilter{Equal: model.RowData{"name": "bob"}}, []model.SortColumn{{Column: "name", Desc: true}},
+			`SELECT "id", "name" FROM "users" WHERE "name" = $1 ORDER BY "name" DESC, "id"`, []interface{}{"bob"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := selectQuery(tt.dialect, "users", columns, tt.filter, tt.sort)
+			if query != tt.wantSQL {
+				t.Errorf("selectQuery() = %q, want %q", query, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("selectQuery() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestReturnsRows(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT 1", true},
+		{"  select * from users", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"SHOW TABLES", true},
+		{"explain select 1", true},
+		{"PRAGMA table_info(users)", true},
+		{"UPDATE users SET name = 'x'", false},
+		{"INSERT INTO users VALUES (1)", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := returnsRows(tt.query); got != tt.want {
+				t.Errorf("returnsRows(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantDeadline bool
+	}{
+		{"no limit", 0, false},
+		{"negative means no limit", -time.Second, false},
+		{"limited", time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := WithTimeout(context.Background(), tt.timeout)
+			if _, ok := ctx.Deadline(); ok != tt.wantDeadline {
+				t.Errorf("WithTimeout(%s) has deadline = %v, want %v", tt.timeout, ok, tt.wantDeadline)
+			}
+
+			cancel()
+			if !errors.Is(ctx.Err(), context.Canceled) {
+				t.Errorf("WithTimeout(%s) after cancel: err = %v, want context.Canceled", tt.timeout, ctx.Err())
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		readOnly     bool
+		query        string
+		wantRows     int
+		wantAffected int64
+		wantErr      bool
+	}{
+		{"select", false, "SELECT ID, Username FROM users WHERE Active = 1", 4, 0, false},
+		{"update", false, "UPDATE users SET Active = 1 WHERE ID <= 3", 0, 3, false},
+		{"syntax error", false, "SELEC 1", 0, 0, true},
+		{"read-only select", true, "SELECT * FROM orders", 5, 0, false},
+		{"read-only update", true, "UPDATE users SET Active = 0", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDemoManager(config.DBConfig{ReadOnly: tt.readOnly})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+
+			result, err := m.Query(context.Background(), tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Query(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(result.Rows) != tt.wantRows {
+				t.Errorf("Query(%q) returned %d rows, want %d", tt.query, len(result.Rows), tt.wantRows)
+			}
+			if result.RowsAffected != tt.wantAffected {
+				t.Errorf("Query(%q) affected %d rows, want %d", tt.query, result.RowsAffected, tt.wantAffected)
+			}
+		})
+	}
+}
+
+func TestSwitchDatabase(t *testing.T) {
+	cfg := config.DBConfig{Database: "shop", Schema: "public"}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		target  string
+		want    config.DBConfig
+		wantErr bool
+	}{
+		{"mysql database", MySQL{}, "crm", config.DBConfig{Database: "crm", Schema: "public"}, false},
+		{"postgres schema", Postgres{}, "sales", config.DBConfig{Database: "shop", Schema: "sales"}, false},
+		{"sqlite main", SQLite{}, "main", cfg, false},
+		{"sqlite attached", SQLite{}, "archive", cfg, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dialect.SwitchDatabase(cfg, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SwitchDatabase(%q) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SwitchDatabase(%q) = %+v, want %+v", tt.target, got, tt.want)
+			}
+			if current := tt.dialect.CurrentDatabase(got); !tt.wantErr && current != tt.target {
+				t.Errorf("CurrentDatabase() after switching = %q, want %q", current, tt.target)
+			}
+		})
+	}
+}
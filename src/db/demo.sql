@@ -0,0 +1,61 @@
+-- Demo database used when no server is reachable. Loaded into an in-memory
+-- SQLite database on startup, so edits are real but not persisted.
+
+CREATE TABLE users (
+	ID INTEGER PRIMARY KEY,
+	Username VARCHAR(50) NOT NULL UNIQUE,
+	Email VARCHAR(100) NOT NULL UNIQUE,
+	Active BOOLEAN NOT NULL DEFAULT 1
+);
+
+CREATE TABLE orders (
+	ID INTEGER PRIMARY KEY,
+	UserID INTEGER NOT NULL REFERENCES users (ID),
+	TotalPrice DECIMAL(10,2) NOT NULL DEFAULT 0,
+	Status VARCHAR(20) NOT NULL DEFAULT 'Pending'
+);
+
+CREATE INDEX idx_user_id ON orders (UserID);
+
+CREATE TABLE products (
+	ID INTEGER PRIMARY KEY,
+	Name VARCHAR(100) NOT NULL,
+	Price DECIMAL(10,2) NOT NULL,
+	Category VARCHAR(50) NOT NULL
+);
+
+CREATE INDEX idx_category ON products (Category);
+
+CREATE TABLE categories (
+	ID INTEGER PRIMARY KEY,
+	Name VARCHAR(50) NOT NULL,
+	Slug VARCHAR(50) NOT NULL UNIQUE
+);
+
+INSERT INTO users (ID, Username, Email, Active) VALUES
+	(1, 'johndoe', 'john@example.com', 1),
+	(2, 'janedoe', 'jane@example.com', 1),
+	(3, 'bobsmith', 'bob@example.com', 0),
+	(4, 'alicejones', 'alice@example.com', 1),
+	(5, 'mikebrown', 'mike@example.com', 1);
+
+INSERT INTO orders (ID, UserID, TotalPrice, Status) VALUES
+	(101, 1, 125.99, 'Completed'),
+	(102, 2, 89.50, 'Processing'),
+	(103, 1, 45.75, 'Shipped'),
+	(104, 3, 210.25, 'Pending'),
+	(105, 4, 55.00, 'Completed');
+
+INSERT INTO products (ID, Name, Price, Category) VALUES
+	(201, 'Laptop', 999.99, 'Electronics'),
+	(202, 'Headphones', 129.99, 'Electronics'),
+	(203, 'Coffee Maker', 79.50, 'Appliances'),
+	(204, 'Running Shoes', 89.95, 'Footwear'),
+	(205, 'Desk Chair', 199.99, 'Furniture');
+
+INSERT INTO categories (ID, Name, Slug) VALUES
+	(301, 'Electronics', 'electronics'),
+	(302, 'Appliances', 'appliances'),
+	(303, 'Footwear', 'footwear'),
+	(304, 'Furniture', 'furniture'),
+	(305, 'Books', 'books');
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+// Dialect captures everything that differs between database servers: how to connect,
+// how to read the catalog, how to quote and parameterize SQL, and how to cancel a
+// running statement
+type Dialect interface {
+	// Connector prepares connections to the configured server, opening the network
+	// connection with dial when it is not nil
+	Connector(cfg config.DBConfig, dial DialFunc) (driver.Connector, error)
+
+	// Init prepares a freshly opened connection pool
+	Init(ctx context.Context, db *sql.DB) error
+
+	// Databases lists the databases (schemas for PostgreSQL) that can be switched to
+	Databases(ctx context.Context, q querier) ([]string, error)
+
+	// CurrentDatabase names the database or schema the configuration points at
+	CurrentDatabase(cfg config.DBConfig) string
+
+	// SwitchDatabase returns the configuration for browsing another database
+	SwitchDatabase(cfg config.DBConfig, name string) (config.DBConfig, error)
+
+	// Tables lists the tables and views of the current database or schema
+	Tables(ctx context.Context, q querier) ([]model.TableInfo, error)
+
+	// Objects lists the stored procedures, functions, triggers and events of the
+	// current database or schema with their definitions
+	Objects(ctx context.Context, q querier) ([]model.SchemaObject, error)
+
+	// Columns describes the columns of a table, marking key columns as PRI or UNI
+	Columns(ctx context.Context, q querier, table string) ([]model.ColumnMetadata, error)
+
+	// Indices describes the indices of a table with their columns in key order
+	Indices(ctx context.Context, q querier, table string) ([]model.IndexMetadata, error)
+
+	// ForeignKeys lists the foreign keys defined on a table and those referencing it
+	ForeignKeys(ctx context.Context, q querier, table string) ([]model.ForeignKey, error)
+
+	// CreateStatement returns the DDL that recreates a table or view
+	CreateStatement(ctx context.Context, q querier, table string) (string, error)
+
+	// EstimateRowCount returns the server's cheap, possibly stale, row count for a table
+	EstimateRowCount(ctx context.Context, q querier, table string) (int64, error)
+
+	// QuoteIdentifier quotes a table or column name
+	QuoteIdentifier(name string) string
+
+	// Placeholder returns the bind parameter marker for the n-th argument, starting at 1
+	Placeholder(n int) string
+
+	// Paginate restricts a SELECT statement to limit rows starting at offset
+	Paginate(query string, offset, limit int) string
+
+	// ConnectionID identifies the server session behind conn so it can be cancelled
+	ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error)
+
+	// CancelQuery aborts the statement running in the session id, from another connection
+	CancelQuery(ctx context.Context, db *sql.DB, id int64) error
+}
+
+// querier is satisfied by *sql.DB, *sql.Conn and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// DialectFor returns the dialect for a configured driver name
+func DialectFor(driver string) (Dialect, error) {
+	switch driver {
+	case "", "mysql":
+		return MySQL{}, nil
+	case "postgres":
+		return Postgres{}, nil
+	case "sqlite":
+		return SQLite{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported database driver: %s", driver)
+}
+
+// scanStrings reads a single-column result set of names
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("error scanning name: %v", err)
+		}
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating names: %v", err)
+	}
+
+	return names, nil
+}
+
+// scanForeignKeys reads one row per foreign key column, ordered by constraint and
+// column position, with the columns: constraint id, constraint name, table, column,
+// referenced table, referenced column, update rule and delete rule. The id groups
+// the rows of a constraint, since SQLite constraints have no names.
+func scanForeignKeys(rows *sql.Rows) ([]model.ForeignKey, error) {
+	defer rows.Close()
+
+	var keys []model.ForeignKey
+	lastID := ""
+	for rows.Next() {
+		var id, column, refColumn string
+		var fk model.ForeignKey
+		if err := rows.Scan(&id, &fk.Name, &fk.Table, &column, &fk.RefTable, &refColumn, &fk.OnUpdate, &fk.OnDelete); err != nil {
+			return nil, fmt.Errorf("error scanning foreign key: %v", err)
+		}
+
+		id = fk.Table + "." + id
+		if len(keys) == 0 || id != lastID {
+			keys = append(keys, fk)
+			lastID = id
+		}
+		last := &keys[len(keys)-1]
+		last.Columns = append(last.Columns, column)
+		last.RefColumns = append(last.RefColumns, refColumn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %v", err)
+	}
+
+	return keys, nil
+}
+
+// scanTables reads a result set of table names and types
+func scanTables(rows *sql.Rows) ([]model.TableInfo, error) {
+	defer rows.Close()
+
+	var tables []model.TableInfo
+	for rows.Next() {
+		var table model.TableInfo
+		if err := rows.Scan(&table.Name, &table.Type); err != nil {
+			return nil, fmt.Errorf("error scanning table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %v", err)
+	}
+
+	return tables, nil
+}
+
+// queryObjects runs each object query, whose rows are (id, kind, name, detail,
+// definition), then attaches the routine parameters read by paramQuery, whose rows
+// are (id, mode, name, type) in position order. The id only pairs parameters with
+// their routine; paramQuery may be empty when the server has no routines.
+func queryObjects(ctx context.Context, q querier, objectQueries []string, paramQuery string) ([]model.SchemaObject, error) {
+	var objects []model.SchemaObject
+	ids := make(map[string]int)
+	for _, query := range objectQueries {
+		rows, err := q.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching objects: %v", err)
+		}
+		if objects, err = scanObjects(rows, objects, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	if paramQuery == "" {
+		return objects, nil
+	}
+
+	rows, err := q.QueryContext(ctx, paramQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching parameters: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var param model.RoutineParameter
+		if err := rows.Scan(&id, &param.Mode, &param.Name, &param.Type); err != nil {
+			return nil, fmt.Errorf("error scanning parameter: %v", err)
+		}
+		if i, ok := ids[id]; ok {
+			objects[i].Parameters = append(objects[i].Parameters, param)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parameters: %v", err)
+	}
+
+	return objects, nil
+}
+
+// scanObjects appends a result set of schema objects to objects, recording the
+// position of each under its id
+func scanObjects(rows *sql.Rows, objects []model.SchemaObject, ids map[string]int) ([]model.SchemaObject, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var obj model.SchemaObject
+		if err := rows.Scan(&id, &obj.Kind, &obj.Name, &obj.Detail, &obj.Definition); err != nil {
+			return nil, fmt.Errorf("error scanning object: %v", err)
+		}
+		ids[id] = len(objects)
+		objects = append(objects, obj)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating objects: %v", err)
+	}
+
+	return objects, nil
+}
+
+// appendParams adds URL-encoded parameters to a connection string, in key order
+func appendParams(dsn string, params map[string]string) string {
+	for _, key := range sortedKeys(params) {
+		if strings.Contains(dsn, "?") {
+			dsn += "&"
+		} else {
+			dsn += "?"
+		}
+		dsn += key + "=" + url.QueryEscape(params[key])
+	}
+	return dsn
+}
+
+// sortedKeys returns the keys of a parameter map in a stable order
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dsnConnector adapts a driver that only opens connections by data source name
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
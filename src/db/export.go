@@ -0,0 +1,315 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+// ExportFormat is a file format rows can be exported to
+type ExportFormat string
+
+// Constants for export formats
+const (
+	ExportCSV    ExportFormat = "CSV"
+	ExportTSV    ExportFormat = "TSV"
+	ExportJSON   ExportFormat = "JSON"   // A single array of objects
+	ExportNDJSON ExportFormat = "NDJSON" // One object per line
+)
+
+// ExportFormatFor picks the export format from a file name's extension
+func ExportFormatFor(path string) (ExportFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ExportCSV, nil
+	case ".tsv", ".tab":
+		return ExportTSV, nil
+	case ".json":
+		return ExportJSON, nil
+	case ".ndjson", ".jsonl":
+		return ExportNDJSON, nil
+	}
+
+	return "", fmt.Errorf("unknown export format for %s, use .csv, .tsv, .json or .ndjson", filepath.Base(path))
+}
+
+// ExportTable streams every row of a table that matches the filter, in the sort
+// order, to w and returns the number of rows written. Unlike GetTableData the rows
+// are not paginated, and values keep their database types: numbers keep their
+// precision and binary columns are written as base64.
+func (m *Manager) ExportTable(ctx context.Context, w io.Writer, format ExportFormat, tableName string, filter model.RowFilter, sort []model.SortColumn) (int64, error) {
+	columns, err := m.GetTableMetadata(ctx, tableName)
+	if err != nil {
+		return 0, err
+	}
+	query, args := selectQuery(m.dialect, tableName, columns, filter, sort)
+
+	var count int64
+	err = m.withKillableConn(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("error fetching data: %v", err)
+		}
+		defer rows.Close()
+
+		colTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return fmt.Errorf("error getting column types: %v", err)
+		}
+
+		names := make([]string, len(columns))
+		for i, col := range columns {
+			names[i] = col.Name
+		}
+		ew, err := newExportWriter(w, format, names)
+		if err != nil {
+			return err
+		}
+
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(scanArgs...); err != nil {
+				return fmt.Errorf("error scanning row: %v", err)
+			}
+			for i, val := range values {
+				values[i] = exportValue(colTypes[i].DatabaseTypeName(), val)
+			}
+			if err := ew.row(values); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %v", err)
+		}
+
+		return ew.finish()
+	})
+
+	return count, err
+}
+
+// ExportResult writes the rows of a query result to w. Values are taken as the
+// driver returned them, so they are encoded like those of ExportTable.
+func ExportResult(w io.Writer, format ExportFormat, result *model.QueryResult) error {
+	names := make([]string, len(result.Columns))
+	for i, col := range result.Columns {
+		names[i] = col.Name
+	}
+	ew, err := newExportWriter(w, format, names)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(names))
+	for _, raw := range result.RawRows {
+		for i, val := range raw {
+			values[i] = exportValue(strings.ToUpper(result.Columns[i].Type), val)
+		}
+		if err := ew.row(values); err != nil {
+			return err
+		}
+	}
+
+	return ew.finish()
+}
+
+// exportValue converts a scanned value to the type it is exported as. Drivers
+// return many column types as text, so numbers become json.Number to keep their
+// exact digits, JSON columns stay raw JSON and binary columns, or bytes that are
+// not valid UTF-8, stay bytes.
+func exportValue(dbType string, val interface{}) interface{} {
+	b, ok := val.([]byte)
+	if !ok {
+		return val
+	}
+
+	switch dbType {
+	case "INT", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT", "INT2", "INT4", "INT8",
+		"DECIMAL", "FLOAT", "DOUBLE", "NUMERIC", "FLOAT4", "FLOAT8", "REAL", "INTEGER":
+		// PostgreSQL numerics may also be NaN or Infinity, which JSON has no numbers for
+		if len(b) > 0 && (b[0] == '-' || (b[0] >= '0' && b[0] <= '9')) && json.Valid(b) {
+			return json.Number(b)
+		}
+	case "JSON", "JSONB":
+		if json.Valid(b) {
+			return json.RawMessage(b)
+		}
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA":
+		return b
+	}
+
+	// Expressions may have no declared type; bytes that are not text are binary
+	if !utf8.Valid(b) {
+		return b
+	}
+	return string(b)
+}
+
+// exportWriter writes rows in one of the export formats
+type exportWriter struct {
+	w      *bufio.Writer
+	format ExportFormat
+	names  []string
+	rows   int
+}
+
+// newExportWriter starts writing an export of the named columns, writing the
+// header line of the delimited formats
+func newExportWriter(w io.Writer, format ExportFormat, names []string) (*exportWriter, error) {
+	ew := &exportWriter{w: bufio.NewWriter(w), format: format, names: names}
+
+	switch format {
+	case ExportCSV, ExportTSV:
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			values[i] = name
+		}
+		return ew, ew.row(values)
+	case ExportJSON:
+		_, err := ew.w.WriteString("[")
+		return ew, err
+	case ExportNDJSON:
+		return ew, nil
+	}
+
+	return nil, fmt.Errorf("unsupported export format: %s", format)
+}
+
+// row writes one row of values, in column order
+func (ew *exportWriter) row(values []interface{}) error {
+	var line strings.Builder
+	switch ew.format {
+	case ExportCSV:
+		for i, val := range values {
+			if i > 0 {
+				line.WriteString(",")
+			}
+			line.WriteString(csvField(val))
+		}
+		line.WriteString("\n")
+	case ExportTSV:
+		for i, val := range values {
+			if i > 0 {
+				line.WriteString("\t")
+			}
+			line.WriteString(tsvField(val))
+		}
+		line.WriteString("\n")
+	case ExportJSON, ExportNDJSON:
+		if ew.format == ExportJSON {
+			if ew.rows > 0 {
+				line.WriteString(",")
+			}
+			line.WriteString("\n  ")
+		}
+
+		// Keys are written in column order, which encoding/json would sort
+		line.WriteString("{")
+		for i, val := range values {
+			if i > 0 {
+				line.WriteString(",")
+			}
+			key, _ := json.Marshal(ew.names[i])
+			data, err := json.Marshal(val)
+			if err != nil {
+				return fmt.Errorf("error encoding %s: %v", ew.names[i], err)
+			}
+			line.Write(key)
+			line.WriteString(":")
+			line.Write(data)
+		}
+		line.WriteString("}")
+
+		if ew.format == ExportNDJSON {
+			line.WriteString("\n")
+		}
+	}
+
+	ew.rows++
+	if _, err := ew.w.WriteString(line.String()); err != nil {
+		return fmt.Errorf("error writing export: %v", err)
+	}
+	return nil
+}
+
+// finish closes the JSON array and flushes the buffered output
+func (ew *exportWriter) finish() error {
+	if ew.format == ExportJSON {
+		closing := "]\n"
+		if ew.rows > 0 {
+			closing = "\n]\n"
+		}
+		if _, err := ew.w.WriteString(closing); err != nil {
+			return fmt.Errorf("error writing export: %v", err)
+		}
+	}
+
+	if err := ew.w.Flush(); err != nil {
+		return fmt.Errorf("error writing export: %v", err)
+	}
+	return nil
+}
+
+// csvField encodes a value as a CSV field. As in PostgreSQL's CSV format, NULL is
+// an empty unquoted field while an empty string is written as "".
+func csvField(val interface{}) string {
+	if val == nil {
+		return ""
+	}
+
+	text := exportText(val)
+	if text == "" || strings.ContainsAny(text, ",\"\r\n") || text[0] == ' ' || text[len(text)-1] == ' ' {
+		return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+	}
+	return text
+}
+
+// tsvField encodes a value as a TSV field. As in the text format of PostgreSQL's
+// COPY and MySQL's LOAD DATA, NULL is \N and tabs, line breaks and backslashes in
+// values are escaped with a backslash.
+func tsvField(val interface{}) string {
+	if val == nil {
+		return `\N`
+	}
+
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(exportText(val))
+}
+
+// exportText renders a non-NULL value for the delimited formats, in a form that
+// reads back as the same value rather than model.FormatValue's display form
+func exportText(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case json.RawMessage:
+		return string(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprintf("%v", val)
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportFormatFor(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    ExportFormat
+		wantErr bool
+	}{
+		{"users.csv", ExportCSV, false},
+		{"/tmp/Users.CSV", ExportCSV, false},
+		{"users.tsv", ExportTSV, false},
+		{"users.tab", ExportTSV, false},
+		{"users.json", ExportJSON, false},
+		{"users.ndjson", ExportNDJSON, false},
+		{"users.jsonl", ExportNDJSON, false},
+		{"users.xlsx", "", true},
+		{"users", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ExportFormatFor(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportFormatFor(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExportFormatFor(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVField(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		want string
+	}{
+		{"NULL", nil, ""},
+		{"empty string", "", `""`},
+		{"plain", "plain", "plain"},
+		{"comma", "a,b", `"a,b"`},
+		{"quotes", `say "hi"`, `"say ""hi"""`},
+		{"line break", "a\nb", "\"a\nb\""},
+		{"leading space", " a", `" a"`},
+		{"trailing space", "a ", `"a "`},
+		{"bytes", []byte{0xff, 0x00}, "/wA="},
+		{"number", json.Number("1.50"), "1.50"},
+		{"raw JSON", json.RawMessage(`{"a":1}`), `"{""a"":1}"`},
+		{"bool", true, "true"},
+		{"float", 0.1, "0.1"},
+		{"int", int64(-3), "-3"},
+		{"time", time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC), "2024-05-01T12:30:00.0000005Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvField(tt.val); got != tt.want {
+				t.Errorf("csvField(%#v) = %q, want %q", tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTSVField(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		want string
+	}{
+		{"NULL", nil, `\N`},
+		{"empty string", "", ""},
+		{"plain", "a,b", "a,b"},
+		{"tab", "a\tb", `a\tb`},
+		{"line breaks", "a\r\nb", `a\r\nb`},
+		{"backslash", `C:\N`, `C:\\N`},
+		{"bytes", []byte("hi"), "aGk="},
+		{"bool", false, "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tsvField(tt.val); got != tt.want {
+				t.Errorf("tsvField(%#v) = %q, want %q", tt.val, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbType string
+		val    interface{}
+		want   interface{}
+	}{
+		{"NULL", "INT", nil, nil},
+		{"already converted", "INT", int64(5), int64(5)},
+		{"integer text", "BIGINT", []byte("42"), json.Number("42")},
+		{"decimal keeps digits", "DECIMAL", []byte("1.50"), json.Number("1.50")},
+		{"negative numeric", "NUMERIC", []byte("-0.25"), json.Number("-0.25")},
+		{"numeric NaN", "NUMERIC", []byte("NaN"), "NaN"},
+		{"numeric Infinity", "NUMERIC", []byte("-Infinity"), "-Infinity"},
+		{"json", "JSONB", []byte(`{"a":[1,2]}`), json.RawMessage(`{"a":[1,2]}`)},
+		{"invalid json", "JSON", []byte("{"), "{"},
+		{"blob", "BLOB", []byte("abc"), []byte("abc")},
+		{"bytea", "BYTEA", []byte{0x00, 0x01}, []byte{0x00, 0x01}},
+		{"text", "VARCHAR", []byte("héllo"), "héllo"},
+		{"untyped text", "", []byte("abc"), "abc"},
+		{"untyped binary", "", []byte{0xff, 0xfe}, []byte{0xff, 0xfe}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportValue(tt.dbType, tt.val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exportValue(%q, %#v) = %#v, want %#v", tt.dbType, tt.val, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL is the dialect for MySQL and MariaDB servers
+type MySQL struct{}
+
+// Connector parses the DSN back into a driver config, so that extra parameters are
+// validated and the dial function can be plugged in
+func (d MySQL) Connector(cfg config.DBConfig, dial DialFunc) (driver.Connector, error) {
+	dsn, err := d.DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection parameters: %v", err)
+	}
+	if dial != nil {
+		mysqlConfig.DialFunc = dial
+	}
+
+	return mysql.NewConnector(mysqlConfig)
+}
+
+// tlsConfigName is the name the TLS settings are registered under with the driver
+const tlsConfigName = "dbun"
+
+// DSN formats the connection string with UTF-8 character set parameters, connecting
+// over the Unix socket when one is configured. Extra parameters come last so they win.
+func (MySQL) DSN(cfg config.DBConfig) (string, error) {
+	dsn := mysql.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.DBName = cfg.Database
+	dsn.ParseTime = true
+	dsn.ClientFoundRows = true // Report matched rather than changed rows, as ApplyChanges expects
+	dsn.Collation = "utf8mb4_unicode_ci"
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+
+	if cfg.Socket != "" {
+		dsn.Net = "unix"
+		dsn.Addr = cfg.Socket
+	} else {
+		dsn.Net = "tcp"
+		dsn.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	}
+
+	if cfg.TLS.Mode != "" && cfg.TLS.Mode != config.TLSDisabled {
+		tlsConfig, err := newTLSConfig(cfg.TLS, cfg.Host)
+		if err != nil {
+			return "", err
+		}
+		if err := mysql.RegisterTLSConfig(tlsConfigName, tlsConfig); err != nil {
+			return "", fmt.Errorf("error registering TLS config: %v", err)
+		}
+
+		dsn.TLSConfig = tlsConfigName
+		dsn.AllowFallbackToPlaintext = cfg.TLS.Mode == config.TLSPreferred
+	}
+
+	return appendParams(dsn.FormatDSN(), cfg.Params), nil
+}
+
+// Init sets connection parameters for proper UTF-8 handling
+func (MySQL) Init(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, "SET NAMES utf8mb4"); err != nil {
+		return fmt.Errorf("error setting character set: %v", err)
+	}
+	return nil
+}
+
+// Databases lists databases with SHOW DATABASES
+func (MySQL) Databases(ctx context.Context, q querier) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SHOW DATABASES")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching databases: %v", err)
+	}
+
+	return scanStrings(rows)
+}
+
+// CurrentDatabase returns the configured database name
+func (MySQL) CurrentDatabase(cfg config.DBConfig) string {
+	return cfg.Database
+}
+
+// SwitchDatabase points the configuration at another database. A USE statement would
+// only affect one pooled connection, so the pool is reopened with the new default.
+func (MySQL) SwitchDatabase(cfg config.DBConfig, name string) (config.DBConfig, error) {
+	cfg.Database = name
+	return cfg, nil
+}
+
+// Tables lists tables with SHOW FULL TABLES, whose second column is the table type
+func (MySQL) Tables(ctx context.Context, q querier) ([]model.TableInfo, error) {
+	rows, err := q.QueryContext(ctx, "SHOW FULL TABLES")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tables: %v", err)
+	}
+
+	return scanTables(rows)
+}
+
+// Objects lists the stored routines, triggers and events of the current database
+func (MySQL) Objects(ctx context.Context, q querier) ([]model.SchemaObject, error) {
+	routines := `SELECT CONCAT(ROUTINE_TYPE, '.', SPECIFIC_NAME), ROUTINE_TYPE, ROUTINE_NAME,
+			IF(ROUTINE_TYPE = 'FUNCTION', CONCAT('RETURNS ', DTD_IDENTIFIER), ''),
+			COALESCE(ROUTINE_DEFINITION, '')
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = DATABASE() ORDER BY ROUTINE_NAME`
+	triggers := `SELECT TRIGGER_NAME, 'TRIGGER', TRIGGER_NAME,
+			CONCAT(ACTION_TIMING, ' ', EVENT_MANIPULATION, ' ON ', EVENT_OBJECT_TABLE),
+			ACTION_STATEMENT
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = DATABASE() ORDER BY TRIGGER_NAME`
+	events := `SELECT EVENT_NAME, 'EVENT', EVENT_NAME,
+			CONCAT(IF(EVENT_TYPE = 'RECURRING',
+				CONCAT('EVERY ', INTERVAL_VALUE, ' ', INTERVAL_FIELD),
+				CONCAT('AT ', EXECUTE_AT)), ', ', STATUS),
+			EVENT_DEFINITION
+		FROM information_schema.EVENTS
+		WHERE EVENT_SCHEMA = DATABASE() ORDER BY EVENT_NAME`
+	params := `SELECT CONCAT(ROUTINE_TYPE, '.', SPECIFIC_NAME), COALESCE(PARAMETER_MODE, ''),
+			COALESCE(PARAMETER_NAME, ''), DTD_IDENTIFIER
+		FROM information_schema.PARAMETERS
+		WHERE SPECIFIC_SCHEMA = DATABASE() AND ORDINAL_POSITION > 0
+		ORDER BY SPECIFIC_NAME, ORDINAL_POSITION`
+
+	return queryObjects(ctx, q, []string{routines, triggers, events}, params)
+}
+
+// Columns describes a table with SHOW FULL COLUMNS, which reports defaults the same
+// way on MySQL and MariaDB, adding the character set and generation expression from
+// information_schema
+func (d MySQL) Columns(ctx context.Context, q querier, table string) ([]model.ColumnMetadata, error) {
+	query := fmt.Sprintf("SHOW FULL COLUMNS FROM %s", d.QuoteIdentifier(table))
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table metadata: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []model.ColumnMetadata
+	for rows.Next() {
+		var field, dataType, collation, null, key, defaultVal, extra, privileges, comment sql.NullString
+		if err := rows.Scan(&field, &dataType, &collation, &null, &key, &defaultVal, &extra, &privileges, &comment); err != nil {
+			return nil, fmt.Errorf("error scanning column metadata: %v", err)
+		}
+
+		column := model.ColumnMetadata{
+			Name:      field.String,
+			Type:      dataType.String,
+			Nullable:  null.String == "YES",
+			Key:       key.String,
+			Extra:     extra.String,
+			Collation: collation.String,
+			Comment:   comment.String,
+		}
+		if defaultVal.Valid {
+			column.Default = defaultVal.String
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %v", err)
+	}
+	rows.Close()
+
+	if err := d.columnDetails(ctx, q, table, columns); err != nil {
+		return nil, err
+	}
+
+	return columns, nil
+}
+
+// columnDetails fills in the character set and generation expression of columns
+func (MySQL) columnDetails(ctx context.Context, q querier, table string, columns []model.ColumnMetadata) error {
+	query := `SELECT COLUMN_NAME, CHARACTER_SET_NAME, GENERATION_EXPRESSION
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return fmt.Errorf("error fetching column details: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var charset, generated sql.NullString
+		if err := rows.Scan(&name, &charset, &generated); err != nil {
+			return fmt.Errorf("error scanning column details: %v", err)
+		}
+		for i := range columns {
+			if columns[i].Name == name {
+				columns[i].Charset = charset.String
+				columns[i].Generated = generated.String
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating column details: %v", err)
+	}
+
+	return nil
+}
+
+// Indices describes the indices reported by SHOW INDEX, which lists one row per index
+// column. Its columns are looked up by name since they vary across server versions.
+func (d MySQL) Indices(ctx context.Context, q querier, table string) ([]model.IndexMetadata, error) {
+	query := fmt.Sprintf("SHOW INDEX FROM %s", d.QuoteIdentifier(table))
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table indices: %v", err)
+	}
+	defer rows.Close()
+
+	// Get column names from result set
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("error getting index columns: %v", err)
+	}
+
+	// Create a slice of interface{} to hold the values
+	values := make([]interface{}, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	field := func(name string) string {
+		for i, colName := range columns {
+			if strings.EqualFold(colName, name) && values[i] != nil {
+				return model.FormatValue(values[i])
+			}
+		}
+		return ""
+	}
+
+	// Indices are keyed by name but kept in the order the server lists them
+	var indices []model.IndexMetadata
+	positions := make(map[string]int)
+
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("error scanning index data: %v", err)
+		}
+
+		name := field("Key_name")
+		pos, ok := positions[name]
+		if !ok {
+			index := model.IndexMetadata{
+				Name:        name,
+				Primary:     name == "PRIMARY",
+				Unique:      field("Non_unique") == "0",
+				Type:        field("Index_type"),
+				Visible:     field("Visible") != "NO" && field("Ignored") != "YES",
+				Cardinality: -1,
+				Comment:     field("Index_comment"),
+			}
+			if index.Comment == "" {
+				index.Comment = field("Comment")
+			}
+			if cardinality, err := strconv.ParseInt(field("Cardinality"), 10, 64); err == nil {
+				index.Cardinality = cardinality
+			}
+
+			pos = len(indices)
+			positions[name] = pos
+			indices = append(indices, index)
+		}
+
+		// Functional key parts have no column name but an expression instead
+		column := model.IndexColumn{Name: field("Column_name")}
+		if column.Name == "" {
+			column.Name = field("Expression")
+		}
+		column.SubPart, _ = strconv.Atoi(field("Sub_part"))
+		indices[pos].Columns = append(indices[pos].Columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indices: %v", err)
+	}
+
+	return indices, nil
+}
+
+// ForeignKeys reads KEY_COLUMN_USAGE joined with REFERENTIAL_CONSTRAINTS for the
+// rules. Keys referencing another database name the table as database.table.
+func (MySQL) ForeignKeys(ctx context.Context, q querier, table string) ([]model.ForeignKey, error) {
+	query := `SELECT k.CONSTRAINT_NAME, k.CONSTRAINT_NAME, k.TABLE_NAME, k.COLUMN_NAME,
+			IF(k.REFERENCED_TABLE_SCHEMA = k.TABLE_SCHEMA, k.REFERENCED_TABLE_NAME,
+				CONCAT(k.REFERENCED_TABLE_SCHEMA, '.', k.REFERENCED_TABLE_NAME)),
+			k.REFERENCED_COLUMN_NAME, r.UPDATE_RULE, r.DELETE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+			ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA
+			AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+			AND r.TABLE_NAME = k.TABLE_NAME
+		WHERE k.TABLE_SCHEMA = DATABASE()
+			AND (k.TABLE_NAME = ?
+				OR (k.REFERENCED_TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME = ?))
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+	rows, err := q.QueryContext(ctx, query, table, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching foreign keys: %v", err)
+	}
+
+	return scanForeignKeys(rows)
+}
+
+// CreateStatement runs SHOW CREATE TABLE, which answers for views as SHOW CREATE VIEW
+// does, with extra character set columns. The statement is always the second column.
+func (d MySQL) CreateStatement(ctx context.Context, q querier, table string) (string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SHOW CREATE TABLE %s", d.QuoteIdentifier(table)))
+	if err != nil {
+		return "", fmt.Errorf("error fetching create statement: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("error getting create statement columns: %v", err)
+	}
+	if len(columns) < 2 {
+		return "", fmt.Errorf("unexpected SHOW CREATE TABLE result with %d column(s)", len(columns))
+	}
+
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]interface{}, len(columns))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("error fetching create statement: %v", err)
+		}
+		return "", fmt.Errorf("no create statement for %s", table)
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return "", fmt.Errorf("error scanning create statement: %v", err)
+	}
+
+	return values[1].String + ";", nil
+}
+
+// EstimateRowCount reads TABLE_ROWS from information_schema, which may be stale for InnoDB
+func (MySQL) EstimateRowCount(ctx context.Context, q querier, table string) (int64, error) {
+	query := "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+
+	var estimate sql.NullInt64
+	if err := q.QueryRowContext(ctx, query, table).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("error estimating row count: %v", err)
+	}
+
+	return estimate.Int64, nil
+}
+
+// QuoteIdentifier wraps a name in backticks, escaping embedded backticks
+func (MySQL) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Placeholder returns the positional ? marker
+func (MySQL) Placeholder(n int) string {
+	return "?"
+}
+
+// Paginate appends LIMIT and OFFSET
+func (MySQL) Paginate(query string, offset, limit int) string {
+	return query + fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// ConnectionID returns the server thread id of the connection
+func (MySQL) ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+// CancelQuery kills the running statement but keeps the session alive. The driver
+// only abandons the connection on cancellation, and the server would otherwise keep
+// executing the query.
+func (MySQL) CancelQuery(ctx context.Context, db *sql.DB, id int64) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id))
+	return err
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+)
+
+func TestMySQLDSN(t *testing.T) {
+	const options = "clientFoundRows=true&collation=utf8mb4_unicode_ci&parseTime=true"
+
+	tests := []struct {
+		name    string
+		cfg     config.DBConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "tcp",
+			cfg:  config.DBConfig{User: "root", Host: "localhost", Port: 3306, Database: "shop"},
+			want: "root@tcp(localhost:3306)/shop?" + options + "&charset=utf8mb4",
+		},
+		{
+			name: "ipv6 host",
+			cfg:  config.DBConfig{User: "root", Host: "::1", Port: 3307, Database: "shop"},
+			want: "root@tcp([::1]:3307)/shop?" + options + "&charset=utf8mb4",
+		},
+		{
+			name: "socket and params",
+			cfg: config.DBConfig{User: "app", Password: "p@ss", Socket: "/tmp/mysql.sock", Database: "shop",
+				Params: map[string]string{"timeout": "5s"}},
+			want: "app:p@ss@unix(/tmp/mysql.sock)/shop?" + options + "&charset=utf8mb4&timeout=5s",
+		},
+		{
+			name: "tls disabled",
+			cfg:  config.DBConfig{User: "root", Host: "db", Port: 3306, Database: "shop", TLS: config.TLSConfig{Mode: config.TLSDisabled}},
+			want: "root@tcp(db:3306)/shop?" + options + "&charset=utf8mb4",
+		},
+		{
+			name: "tls preferred falls back to plain text",
+			cfg:  config.DBConfig{User: "root", Host: "db", Port: 3306, Database: "shop", TLS: config.TLSConfig{Mode: config.TLSPreferred}},
+			want: "root@tcp(db:3306)/shop?allowFallbackToPlaintext=true&" + options + "&tls=dbun&charset=utf8mb4",
+		},
+		{
+			name: "tls required",
+			cfg:  config.DBConfig{User: "root", Host: "db", Port: 3306, Database: "shop", TLS: config.TLSConfig{Mode: config.TLSRequired}},
+			want: "root@tcp(db:3306)/shop?" + options + "&tls=dbun&charset=utf8mb4",
+		},
+		{
+			name:    "unknown tls mode",
+			cfg:     config.DBConfig{User: "root", Host: "db", Port: 3306, TLS: config.TLSConfig{Mode: "sometimes"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MySQL{}.DSN(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DSN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
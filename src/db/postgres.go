@@ -0,0 +1,539 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+
+	"github.com/lib/pq"
+)
+
+// Postgres is the dialect for PostgreSQL servers. Catalog queries are scoped to
+// current_schema(), which the DSN points at the configured schema.
+type Postgres struct{}
+
+// Connector creates a lib/pq connector, dialing through dial when it is set
+func (d Postgres) Connector(cfg config.DBConfig, dial DialFunc) (driver.Connector, error) {
+	dsn, err := d.DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection parameters: %v", err)
+	}
+	if dial != nil {
+		connector.Dialer(pqDialer(dial))
+	}
+
+	return connector, nil
+}
+
+// pqDialer adapts a DialFunc to lib/pq's dialer interfaces
+type pqDialer DialFunc
+
+func (d pqDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d pqDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d(ctx, network, address)
+}
+
+func (d pqDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+// pgSSLModes maps the TLS modes to lib/pq's sslmode values
+var pgSSLModes = map[string]string{
+	"":                       "disable",
+	config.TLSDisabled:       "disable",
+	config.TLSPreferred:      "prefer",
+	config.TLSRequired:       "require",
+	config.TLSVerifyCA:       "verify-ca",
+	config.TLSVerifyIdentity: "verify-full",
+}
+
+// DSN formats a key/value connection string. The search path is set to the
+// configured schema so unqualified table names resolve there.
+func (Postgres) DSN(cfg config.DBConfig) (string, error) {
+	sslMode, ok := pgSSLModes[cfg.TLS.Mode]
+	if !ok {
+		return "", fmt.Errorf("TLS mode %s is not supported for PostgreSQL", cfg.TLS.Mode)
+	}
+	if cfg.TLS.ServerName != "" {
+		return "", fmt.Errorf("the PostgreSQL driver always verifies the connection host; tls server_name is not supported")
+	}
+
+	schema := cfg.Schema
+	if schema == "" {
+		schema = "public"
+	}
+
+	// lib/pq treats a host starting with a slash as the socket directory
+	host := cfg.Host
+	if cfg.Socket != "" {
+		host = cfg.Socket
+	}
+
+	params := []string{
+		"host=" + pgQuoteValue(host),
+		fmt.Sprintf("port=%d", cfg.Port),
+		"user=" + pgQuoteValue(cfg.User),
+		"password=" + pgQuoteValue(cfg.Password),
+		"dbname=" + pgQuoteValue(cfg.Database),
+		"search_path=" + pgQuoteValue(schema),
+		"sslmode=" + sslMode,
+	}
+
+	// Files left empty fall back to the driver's defaults in ~/.postgresql
+	if cfg.TLS.CA != "" {
+		params = append(params, "sslrootcert="+pgQuoteValue(cfg.TLS.CA))
+	}
+	if cfg.TLS.Cert != "" {
+		params = append(params, "sslcert="+pgQuoteValue(cfg.TLS.Cert))
+	}
+	if cfg.TLS.Key != "" {
+		params = append(params, "sslkey="+pgQuoteValue(cfg.TLS.Key))
+	}
+
+	// Let the server refuse writes as well
+	if cfg.ReadOnly {
+		params = append(params, "default_transaction_read_only=on")
+	}
+
+	// Extra parameters come last so they win, e.g. connect_timeout or sslmode
+	for _, key := range sortedKeys(cfg.Params) {
+		params = append(params, key+"="+pgQuoteValue(cfg.Params[key]))
+	}
+	return strings.Join(params, " "), nil
+}
+
+// Init has nothing to prepare; lib/pq always talks UTF-8
+func (Postgres) Init(ctx context.Context, db *sql.DB) error {
+	return nil
+}
+
+// Databases lists the schemas of the connected database, leaving out system schemas;
+// switching databases would need a new login, schemas are what users browse
+func (Postgres) Databases(ctx context.Context, q querier) ([]string, error) {
+	query := `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT LIKE 'pg\_%' AND schema_name <> 'information_schema'
+		ORDER BY schema_name`
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching schemas: %v", err)
+	}
+
+	return scanStrings(rows)
+}
+
+// CurrentDatabase returns the configured schema
+func (Postgres) CurrentDatabase(cfg config.DBConfig) string {
+	return cfg.Schema
+}
+
+// SwitchDatabase points the search path at another schema
+func (Postgres) SwitchDatabase(cfg config.DBConfig, name string) (config.DBConfig, error) {
+	cfg.Schema = name
+	return cfg, nil
+}
+
+// Tables lists tables and views of the current schema. Temporary tables are
+// reported as base tables, since they can be edited like any other.
+func (Postgres) Tables(ctx context.Context, q querier) ([]model.TableInfo, error) {
+	query := `SELECT table_name,
+			CASE table_type WHEN 'LOCAL TEMPORARY' THEN 'BASE TABLE' ELSE table_type END
+		FROM information_schema.tables
+		WHERE table_schema = current_schema() ORDER BY table_name`
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tables: %v", err)
+	}
+
+	return scanTables(rows)
+}
+
+// Objects lists the functions, procedures and triggers of the current schema
+func (Postgres) Objects(ctx context.Context, q querier) ([]model.SchemaObject, error) {
+	// Routines that belong to extensions are left out, as they are not the user's own
+	routines := `SELECT p.proname || '_' || p.oid,
+			CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END, p.proname,
+			CASE p.prokind WHEN 'p' THEN '' ELSE 'RETURNS ' || pg_get_function_result(p.oid) END,
+			pg_get_functiondef(p.oid)
+		FROM pg_proc p
+		WHERE p.pronamespace = current_schema()::regnamespace AND p.prokind IN ('f', 'p')
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d
+				WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+		ORDER BY p.proname, p.oid`
+	triggers := `SELECT event_object_table || '.' || trigger_name, 'TRIGGER', trigger_name,
+			action_timing || ' ' || string_agg(event_manipulation, ' OR ' ORDER BY event_manipulation)
+				|| ' ON ' || event_object_table,
+			action_statement
+		FROM information_schema.triggers
+		WHERE trigger_schema = current_schema()
+		GROUP BY trigger_name, event_object_table, action_timing, action_statement
+		ORDER BY trigger_name, event_object_table`
+	params := `SELECT specific_name, parameter_mode, COALESCE(parameter_name, ''),
+			CASE data_type WHEN 'USER-DEFINED' THEN udt_name ELSE data_type END
+		FROM information_schema.parameters
+		WHERE specific_schema = current_schema()
+		ORDER BY specific_name, ordinal_position`
+
+	// PostgreSQL has no scheduled events
+	return queryObjects(ctx, q, []string{routines, triggers}, params)
+}
+
+// Columns reads information_schema.columns, deriving the PRI/UNI key markers from
+// the table's primary key and unique constraints, and identity, serial and generated
+// columns from their defaults. Generation expressions need PostgreSQL 12 or later.
+func (Postgres) Columns(ctx context.Context, q querier, table string) ([]model.ColumnMetadata, error) {
+	// 'PRIMARY KEY' sorts before 'UNIQUE', so a column in both reports PRI. Only
+	// unique constraints of a single column make it UNI, since a column of a
+	// composite one may repeat values and cannot identify a row on its own.
+	query := `SELECT c.column_name,
+			CASE WHEN c.character_maximum_length IS NOT NULL
+				THEN c.data_type || '(' || c.character_maximum_length || ')'
+				ELSE c.data_type END,
+			c.is_nullable,
+			COALESCE((
+				SELECT CASE tc.constraint_type WHEN 'PRIMARY KEY' THEN 'PRI' ELSE 'UNI' END
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON kcu.constraint_schema = tc.constraint_schema
+					AND kcu.constraint_name = tc.constraint_name
+				WHERE tc.table_schema = c.table_schema
+					AND tc.table_name = c.table_name
+					AND kcu.column_name = c.column_name
+					AND (tc.constraint_type = 'PRIMARY KEY' OR (tc.constraint_type = 'UNIQUE'
+						AND (SELECT count(*) FROM information_schema.key_column_usage other
+							WHERE other.constraint_schema = tc.constraint_schema
+								AND other.constraint_name = tc.constraint_name) = 1))
+				ORDER BY tc.constraint_type
+				LIMIT 1
+			), ''),
+			c.column_default,
+			CASE WHEN c.is_identity = 'YES' THEN 'identity'
+				WHEN c.is_generated = 'ALWAYS' THEN 'STORED GENERATED'
+				WHEN c.column_default LIKE 'nextval(%' THEN 'serial'
+				ELSE '' END,
+			COALESCE(c.character_set_name, ''),
+			COALESCE(c.collation_name, ''),
+			COALESCE(col_description(format('%I.%I', c.table_schema, c.table_name)::regclass,
+				c.ordinal_position), ''),
+			COALESCE(c.generation_expression, '')
+		FROM information_schema.columns c
+		WHERE c.table_schema = current_schema() AND c.table_name = $1
+		ORDER BY c.ordinal_position`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table metadata: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []model.ColumnMetadata
+	for rows.Next() {
+		var name, dataType, nullable, key string
+		var defaultVal sql.NullString
+		var column model.ColumnMetadata
+		if err := rows.Scan(&name, &dataType, &nullable, &key, &defaultVal,
+			&column.Extra, &column.Charset, &column.Collation, &column.Comment, &column.Generated); err != nil {
+			return nil, fmt.Errorf("error scanning column metadata: %v", err)
+		}
+
+		column.Name = name
+		column.Type = dataType
+		column.Nullable = nullable == "YES"
+		column.Key = key
+		if defaultVal.Valid {
+			column.Default = pgDefault(defaultVal.String)
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %v", err)
+	}
+
+	return columns, nil
+}
+
+// Indices describes the indices of a table from pg_index. PostgreSQL has no prefix
+// or invisible indices; the cardinality is the index's tuple estimate, which is -1
+// until the table is analyzed.
+func (Postgres) Indices(ctx context.Context, q querier, table string) ([]model.IndexMetadata, error) {
+	query := `SELECT i.relname, ix.indisprimary, ix.indisunique, upper(am.amname),
+			ARRAY(SELECT pg_get_indexdef(ix.indexrelid, k, true)
+				FROM generate_series(1, ix.indnatts) k ORDER BY k),
+			i.reltuples::bigint, COALESCE(obj_description(i.oid, 'pg_class'), '')
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_am am ON am.oid = i.relam
+		WHERE n.nspname = current_schema() AND t.relname = $1
+		ORDER BY ix.indisprimary DESC, i.relname`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table indices: %v", err)
+	}
+	defer rows.Close()
+
+	var indices []model.IndexMetadata
+	for rows.Next() {
+		index := model.IndexMetadata{Visible: true}
+		var columns []string
+		if err := rows.Scan(&index.Name, &index.Primary, &index.Unique, &index.Type,
+			pq.Array(&columns), &index.Cardinality, &index.Comment); err != nil {
+			return nil, fmt.Errorf("error scanning index data: %v", err)
+		}
+		for _, column := range columns {
+			index.Columns = append(index.Columns, model.IndexColumn{Name: column})
+		}
+		indices = append(indices, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indices: %v", err)
+	}
+
+	return indices, nil
+}
+
+// ForeignKeys reads foreign key constraints from pg_constraint, which keeps the
+// column pairs in order. Tables outside the current schema are schema qualified.
+func (Postgres) ForeignKeys(ctx context.Context, q querier, table string) ([]model.ForeignKey, error) {
+	query := `SELECT con.conname,
+			CASE WHEN src.relnamespace = current_schema()::regnamespace THEN src.relname
+				ELSE src.relnamespace::regnamespace::text || '.' || src.relname END,
+			ARRAY(SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.n),
+			CASE WHEN dst.relnamespace = current_schema()::regnamespace THEN dst.relname
+				ELSE dst.relnamespace::regnamespace::text || '.' || dst.relname END,
+			ARRAY(SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY k(attnum, n)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.n),
+			con.confupdtype, con.confdeltype
+		FROM pg_constraint con
+		JOIN pg_class src ON src.oid = con.conrelid
+		JOIN pg_class dst ON dst.oid = con.confrelid
+		WHERE con.contype = 'f'
+			AND ((src.relname = $1 AND src.relnamespace = current_schema()::regnamespace)
+				OR (dst.relname = $1 AND dst.relnamespace = current_schema()::regnamespace))
+		ORDER BY 2, con.conname`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching foreign keys: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []model.ForeignKey
+	for rows.Next() {
+		var fk model.ForeignKey
+		var onUpdate, onDelete string
+		if err := rows.Scan(&fk.Name, &fk.Table, pq.Array(&fk.Columns), &fk.RefTable, pq.Array(&fk.RefColumns),
+			&onUpdate, &onDelete); err != nil {
+			return nil, fmt.Errorf("error scanning foreign key: %v", err)
+		}
+		fk.OnUpdate = pgReferentialActions[onUpdate]
+		fk.OnDelete = pgReferentialActions[onDelete]
+		keys = append(keys, fk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign keys: %v", err)
+	}
+
+	return keys, nil
+}
+
+// pgReferentialActions names the action codes of pg_constraint
+var pgReferentialActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// CreateStatement rebuilds the DDL of a table from the catalog, since PostgreSQL has
+// no SHOW CREATE TABLE: columns, constraints and the indices that back no constraint.
+// Views are reported with their stored definition.
+func (d Postgres) CreateStatement(ctx context.Context, q querier, table string) (string, error) {
+	var oid int64
+	var kind, viewDef string
+	query := `SELECT c.oid, c.relkind,
+			CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid, true) ELSE '' END
+		FROM pg_class c
+		WHERE c.oid = to_regclass(format('%I.%I', current_schema(), $1::text))`
+	if err := q.QueryRowContext(ctx, query, table).Scan(&oid, &kind, &viewDef); err != nil {
+		return "", fmt.Errorf("error fetching create statement: %v", err)
+	}
+
+	switch kind {
+	case "v":
+		return fmt.Sprintf("CREATE VIEW %s AS\n%s", d.QuoteIdentifier(table), viewDef), nil
+	case "m":
+		return fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS\n%s", d.QuoteIdentifier(table), viewDef), nil
+	}
+
+	var definitions []string
+
+	rows, err := q.QueryContext(ctx, `SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			a.attidentity, a.attgenerated, COALESCE(pg_get_expr(ad.adbin, ad.adrelid), '')
+		FROM pg_attribute a
+		LEFT JOIN pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+		WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY a.attnum`, oid)
+	if err != nil {
+		return "", fmt.Errorf("error fetching create statement columns: %v", err)
+	}
+	for rows.Next() {
+		var name, dataType, identity, generated, expr string
+		var notNull bool
+		if err := rows.Scan(&name, &dataType, &notNull, &identity, &generated, &expr); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("error scanning create statement columns: %v", err)
+		}
+
+		definition := d.QuoteIdentifier(name) + " " + dataType
+		if notNull {
+			definition += " NOT NULL"
+		}
+		switch {
+		case identity == "a":
+			definition += " GENERATED ALWAYS AS IDENTITY"
+		case identity == "d":
+			definition += " GENERATED BY DEFAULT AS IDENTITY"
+		case generated == "s":
+			definition += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", expr)
+		case expr != "":
+			definition += " DEFAULT " + expr
+		}
+		definitions = append(definitions, definition)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return "", fmt.Errorf("error iterating create statement columns: %v", err)
+	}
+
+	rows, err = q.QueryContext(ctx, `SELECT conname, pg_get_constraintdef(oid, true)
+		FROM pg_constraint WHERE conrelid = $1
+		ORDER BY contype = 'p' DESC, contype = 'f', conname`, oid)
+	if err != nil {
+		return "", fmt.Errorf("error fetching create statement constraints: %v", err)
+	}
+	for rows.Next() {
+		var name, definition string
+		if err := rows.Scan(&name, &definition); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("error scanning create statement constraints: %v", err)
+		}
+		definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s %s", d.QuoteIdentifier(name), definition))
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return "", fmt.Errorf("error iterating create statement constraints: %v", err)
+	}
+
+	statement := fmt.Sprintf("CREATE TABLE %s (\n    %s\n);", d.QuoteIdentifier(table), strings.Join(definitions, ",\n    "))
+
+	rows, err = q.QueryContext(ctx, `SELECT pg_get_indexdef(i.indexrelid)
+		FROM pg_index i
+		WHERE i.indrelid = $1
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid)
+		ORDER BY i.indexrelid::regclass::text`, oid)
+	if err != nil {
+		return "", fmt.Errorf("error fetching create statement indices: %v", err)
+	}
+	indices, err := scanStrings(rows)
+	if err != nil {
+		return "", err
+	}
+	for _, index := range indices {
+		statement += "\n\n" + index + ";"
+	}
+
+	return statement, nil
+}
+
+// EstimateRowCount reads the planner statistics in pg_class; tables that were never
+// analyzed report -1, which is treated as unknown
+func (Postgres) EstimateRowCount(ctx context.Context, q querier, table string) (int64, error) {
+	query := `SELECT c.reltuples::bigint FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relname = $1`
+
+	var estimate sql.NullInt64
+	if err := q.QueryRowContext(ctx, query, table).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("error estimating row count: %v", err)
+	}
+
+	if estimate.Int64 < 0 {
+		return 0, nil
+	}
+	return estimate.Int64, nil
+}
+
+// QuoteIdentifier wraps a name in double quotes, escaping embedded quotes
+func (Postgres) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Placeholder returns the numbered $n marker
+func (Postgres) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// Paginate appends LIMIT and OFFSET
+func (Postgres) Paginate(query string, offset, limit int) string {
+	return query + fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// ConnectionID returns the backend process id of the connection
+func (Postgres) ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var id int64
+	err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&id)
+	return id, err
+}
+
+// CancelQuery cancels the statement running in a backend. lib/pq already sends a
+// cancel request when the context ends, so this is a fallback.
+func (Postgres) CancelQuery(ctx context.Context, db *sql.DB, id int64) error {
+	_, err := db.ExecContext(ctx, "SELECT pg_cancel_backend($1)", id)
+	return err
+}
+
+// pgDefault strips the type cast from a literal column default such as
+// 'draft'::character varying; expressions like nextval(...) are returned as is
+func pgDefault(expr string) string {
+	if !strings.HasPrefix(expr, "'") {
+		return expr
+	}
+
+	end := strings.LastIndex(expr, "'::")
+	if end <= 0 {
+		return expr
+	}
+	return strings.ReplaceAll(expr[1:end], "''", "'")
+}
+
+// pgQuoteValue quotes a connection string value, escaping backslashes and quotes
+func pgQuoteValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	base := config.DBConfig{User: "app", Password: "secret", Host: "localhost", Port: 5432, Database: "shop"}
+	with := func(change func(cfg *config.DBConfig)) config.DBConfig {
+		cfg := base
+		change(&cfg)
+		return cfg
+	}
+	const target = "host='localhost' port=5432 user='app' password='secret' dbname='shop' "
+
+	tests := []struct {
+		name    string
+		cfg     config.DBConfig
+		want    string
+		wantErr bool
+	}{
+		{"defaults", base, target + "search_path='public' sslmode=disable", false},
+		{"schema", with(func(cfg *config.DBConfig) { cfg.Schema = "sales" }), target + "search_path='sales' sslmode=disable", false},
+		{"quoted values", with(func(cfg *config.DBConfig) { cfg.Password = `it's a \ test` }),
+			`host='localhost' port=5432 user='app' password='it\'s a \\ test' dbname='shop' search_path='public' sslmode=disable`, false},
+		{"socket directory", with(func(cfg *config.DBConfig) { cfg.Socket = "/var/run/postgresql" }),
+			"host='/var/run/postgresql' port=5432 user='app' password='secret' dbname='shop' search_path='public' sslmode=disable", false},
+		{"tls disabled", with(func(cfg *config.DBConfig) { cfg.TLS.Mode = config.TLSDisabled }), target + "search_path='public' sslmode=disable", false},
+		{"tls preferred", with(func(cfg *config.DBConfig) { cfg.TLS.Mode = config.TLSPreferred }), target + "search_path='public' sslmode=prefer", false},
+		{"tls required", with(func(cfg *config.DBConfig) { cfg.TLS.Mode = config.TLSRequired }), target + "search_path='public' sslmode=require", false},
+		{"tls verify-ca", with(func(cfg *config.DBConfig) { cfg.TLS.Mode = config.TLSVerifyCA }), target + "search_path='public' sslmode=verify-ca", false},
+		{"tls verify-identity with files", with(func(cfg *config.DBConfig) {
+			cfg.TLS = config.TLSConfig{Mode: config.TLSVerifyIdentity, CA: "/ca.pem", Cert: "/cert.pem", Key: "/key.pem"}
+		}), target + "search_path='public' sslmode=verify-full sslrootcert='/ca.pem' sslcert='/cert.pem' sslkey='/key.pem'", false},
+		{"read-only", with(func(cfg *config.DBConfig) { cfg.ReadOnly = true }),
+			target + "search_path='public' sslmode=disable default_transaction_read_only=on", false},
+		{"params come last", with(func(cfg *config.DBConfig) {
+			cfg.Params = map[string]string{"sslmode": "require", "connect_timeout": "5"}
+		}),
+			target + "search_path='public' sslmode=disable connect_timeout='5' sslmode='require'", false},
+		{"unknown tls mode", with(func(cfg *config.DBConfig) { cfg.TLS.Mode = "sometimes" }), "", true},
+		{"server name", with(func(cfg *config.DBConfig) { cfg.TLS = config.TLSConfig{Mode: config.TLSRequired, ServerName: "db"} }), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Postgres{}.DSN(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DSN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
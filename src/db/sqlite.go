@@ -0,0 +1,446 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// demoDSN names a shared in-memory database, so it lives as long as the pool does
+const demoDSN = "file:dbun-demo?mode=memory&cache=shared&_foreign_keys=on"
+
+//go:embed demo.sql
+var demoSQL string
+
+// SQLite is the dialect for SQLite database files; the database name is the file path
+type SQLite struct{}
+
+// NewDemoManager opens an in-memory SQLite database seeded with demo tables
+func NewDemoManager(cfg config.DBConfig) (*Manager, error) {
+	cfg.Driver = "sqlite"
+	cfg.Database = demoDSN
+	cfg.SSH = config.SSHConfig{}
+
+	m, err := NewManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := WithTimeout(context.Background(), cfg.QueryTimeout)
+	defer cancel()
+
+	if _, err := m.db.ExecContext(ctx, demoSQL); err != nil {
+		m.Close()
+		return nil, fmt.Errorf("error loading demo database: %v", err)
+	}
+
+	return m, nil
+}
+
+// Connector opens the database file; SQLite has no network connection to tunnel
+func (d SQLite) Connector(cfg config.DBConfig, dial DialFunc) (driver.Connector, error) {
+	if dial != nil {
+		return nil, fmt.Errorf("SSH tunnels are not supported for SQLite databases")
+	}
+
+	dsn, err := d.DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsnConnector{dsn: dsn, driver: &sqlite3.SQLiteDriver{}}, nil
+}
+
+// DSN turns the file path into a URI that refuses to create missing files and
+// opens the file read-only for read-only connections.
+// Values that already are file: URIs are used as is.
+func (SQLite) DSN(cfg config.DBConfig) (string, error) {
+	if strings.HasPrefix(cfg.Database, "file:") {
+		return cfg.Database, nil
+	}
+
+	mode := "rw"
+	if cfg.ReadOnly {
+		mode = "ro"
+	}
+
+	path := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(cfg.Database)
+	return appendParams("file:"+path+"?mode="+mode+"&_foreign_keys=on&_busy_timeout=5000", cfg.Params), nil
+}
+
+// Init limits the pool to a single connection: SQLite serializes writers anyway,
+// and one connection keeps an in-memory database alive and consistent
+func (SQLite) Init(ctx context.Context, db *sql.DB) error {
+	db.SetMaxOpenConns(1)
+	return nil
+}
+
+// Databases lists the main database and any attached ones
+func (SQLite) Databases(ctx context.Context, q querier) ([]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_database_list ORDER BY seq")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching databases: %v", err)
+	}
+
+	return scanStrings(rows)
+}
+
+// CurrentDatabase is always the main database of the file
+func (SQLite) CurrentDatabase(cfg config.DBConfig) string {
+	return "main"
+}
+
+// SwitchDatabase is not supported: a SQLite connection browses a single file
+func (SQLite) SwitchDatabase(cfg config.DBConfig, name string) (config.DBConfig, error) {
+	if name == "main" {
+		return cfg, nil
+	}
+	return cfg, fmt.Errorf("switching to attached database %s is not supported", name)
+}
+
+// Tables lists tables and views from sqlite_master, skipping internal tables
+func (SQLite) Tables(ctx context.Context, q querier) ([]model.TableInfo, error) {
+	query := `SELECT name, CASE type WHEN 'view' THEN 'VIEW' ELSE 'BASE TABLE' END
+		FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching tables: %v", err)
+	}
+
+	return scanTables(rows)
+}
+
+// Objects lists the triggers of the database
+func (SQLite) Objects(ctx context.Context, q querier) ([]model.SchemaObject, error) {
+	// SQLite has triggers, but no stored routines or events
+	triggers := `SELECT name, 'TRIGGER', name, 'ON ' || tbl_name, COALESCE(sql, '')
+		FROM sqlite_master WHERE type = 'trigger' ORDER BY name`
+
+	return queryObjects(ctx, q, []string{triggers}, "")
+}
+
+// Columns reads PRAGMA table_xinfo, marking primary key columns as PRI and
+// columns covered by a single-column unique index as UNI. SQLite keeps no comments
+// or generation expressions outside of the CREATE TABLE statement.
+func (d SQLite) Columns(ctx context.Context, q querier, table string) ([]model.ColumnMetadata, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_xinfo(%s)", d.QuoteIdentifier(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table metadata: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []model.ColumnMetadata
+	rowid, primaryKeys := -1, 0
+	for rows.Next() {
+		var cid, notNull, pk, hidden int
+		var name, dataType string
+		var defaultVal sql.NullString
+		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultVal, &pk, &hidden); err != nil {
+			return nil, fmt.Errorf("error scanning column metadata: %v", err)
+		}
+
+		column := model.ColumnMetadata{
+			Name:     name,
+			Type:     strings.ToLower(dataType),
+			Nullable: notNull == 0 && pk == 0,
+		}
+		// Hidden columns are 1 for virtual table internals, 2 and 3 for generated ones
+		switch hidden {
+		case 1:
+			continue
+		case 2:
+			column.Extra = "VIRTUAL GENERATED"
+		case 3:
+			column.Extra = "STORED GENERATED"
+		}
+		if defaultVal.Valid {
+			column.Default = sqliteDefault(defaultVal.String)
+		}
+		if pk > 0 {
+			column.Key = "PRI"
+			primaryKeys++
+			if column.Type == "integer" {
+				rowid = len(columns)
+			}
+		}
+		columns = append(columns, column)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns: %v", err)
+	}
+	rows.Close()
+
+	// A lone INTEGER PRIMARY KEY aliases the rowid and is assigned automatically
+	if rowid >= 0 && primaryKeys == 1 {
+		columns[rowid].Extra = "rowid"
+	}
+
+	unique, err := d.uniqueColumns(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		if columns[i].Key == "" && unique[columns[i].Name] {
+			columns[i].Key = "UNI"
+		}
+	}
+
+	return columns, nil
+}
+
+// uniqueColumns returns the columns that have a unique index of their own
+func (d SQLite) uniqueColumns(ctx context.Context, q querier, table string) (map[string]bool, error) {
+	indices, err := d.indexList(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]bool)
+	for _, index := range indices {
+		if !index.unique || index.partial {
+			continue
+		}
+
+		cols, err := d.indexColumns(ctx, q, index.name)
+		if err != nil {
+			return nil, err
+		}
+		if len(cols) == 1 {
+			unique[cols[0].Name] = true
+		}
+	}
+
+	return unique, nil
+}
+
+// Indices describes the indices from PRAGMA index_list. An INTEGER PRIMARY KEY is the
+// rowid itself and has no index of its own, so it is reported as the PRIMARY index.
+func (d SQLite) Indices(ctx context.Context, q querier, table string) ([]model.IndexMetadata, error) {
+	list, err := d.indexList(ctx, q, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []model.IndexMetadata
+	hasPrimary := false
+	for _, entry := range list {
+		index := model.IndexMetadata{
+			Name:        entry.name,
+			Primary:     entry.origin == "pk",
+			Unique:      entry.unique,
+			Type:        "BTREE",
+			Visible:     true,
+			Cardinality: -1,
+		}
+		if entry.partial {
+			index.Comment = "partial"
+		}
+		index.Columns, err = d.indexColumns(ctx, q, entry.name)
+		if err != nil {
+			return nil, err
+		}
+		hasPrimary = hasPrimary || index.Primary
+		indices = append(indices, index)
+	}
+
+	if !hasPrimary {
+		columns, err := d.Columns(ctx, q, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			if column.Key == "PRI" {
+				primary := model.IndexMetadata{
+					Name:        "PRIMARY",
+					Columns:     []model.IndexColumn{{Name: column.Name}},
+					Primary:     true,
+					Unique:      true,
+					Type:        "ROWID",
+					Visible:     true,
+					Cardinality: -1,
+				}
+				indices = append([]model.IndexMetadata{primary}, indices...)
+				break
+			}
+		}
+	}
+
+	return indices, nil
+}
+
+// sqliteIndex is one entry of PRAGMA index_list
+type sqliteIndex struct {
+	name    string
+	unique  bool
+	origin  string // c for CREATE INDEX, u for UNIQUE constraints, pk for PRIMARY KEY
+	partial bool
+}
+
+// indexList reads PRAGMA index_list
+func (d SQLite) indexList(ctx context.Context, q querier, table string) ([]sqliteIndex, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA index_list(%s)", d.QuoteIdentifier(table)))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching table indices: %v", err)
+	}
+	defer rows.Close()
+
+	var indices []sqliteIndex
+	for rows.Next() {
+		var seq, unique, partial int
+		var index sqliteIndex
+		if err := rows.Scan(&seq, &index.name, &unique, &index.origin, &partial); err != nil {
+			return nil, fmt.Errorf("error scanning index data: %v", err)
+		}
+		index.unique = unique != 0
+		index.partial = partial != 0
+		indices = append(indices, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating indices: %v", err)
+	}
+
+	return indices, nil
+}
+
+// indexColumns reads the key columns of an index from PRAGMA index_info
+func (d SQLite) indexColumns(ctx context.Context, q querier, index string) ([]model.IndexColumn, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA index_info(%s)", d.QuoteIdentifier(index)))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching index columns: %v", err)
+	}
+	defer rows.Close()
+
+	var columns []model.IndexColumn
+	for rows.Next() {
+		var seqno, cid int
+		var name sql.NullString
+		if err := rows.Scan(&seqno, &cid, &name); err != nil {
+			return nil, fmt.Errorf("error scanning index columns: %v", err)
+		}
+		// Expressions have no column name; cid -1 is the rowid and -2 an expression
+		switch {
+		case name.Valid:
+			columns = append(columns, model.IndexColumn{Name: name.String})
+		case cid == -1:
+			columns = append(columns, model.IndexColumn{Name: "rowid"})
+		default:
+			columns = append(columns, model.IndexColumn{Name: "<expression>"})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating index columns: %v", err)
+	}
+
+	return columns, nil
+}
+
+// ForeignKeys joins every table with pragma_foreign_key_list. Keys that reference
+// the parent's primary key implicitly report no parent columns, so those are looked up.
+func (d SQLite) ForeignKeys(ctx context.Context, q querier, table string) ([]model.ForeignKey, error) {
+	query := `SELECT f.id, '', m.name, f."from", f."table", COALESCE(f."to", ''),
+			f.on_update, f.on_delete
+		FROM sqlite_master m
+		JOIN pragma_foreign_key_list(m.name) f
+		WHERE m.type = 'table' AND (m.name = ? OR f."table" = ?)
+		ORDER BY m.name, f.id, f.seq`
+	rows, err := q.QueryContext(ctx, query, table, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching foreign keys: %v", err)
+	}
+
+	keys, err := scanForeignKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, fk := range keys {
+		if fk.RefColumns[0] != "" {
+			continue
+		}
+		columns, err := d.Columns(ctx, q, fk.RefTable)
+		if err != nil {
+			return nil, err
+		}
+		keys[i].RefColumns = KeyColumns(columns)
+	}
+
+	return keys, nil
+}
+
+// CreateStatement returns the statements SQLite stored in sqlite_master for a table
+// or view, followed by those of its indices and triggers
+func (SQLite) CreateStatement(ctx context.Context, q querier, table string) (string, error) {
+	query := `SELECT sql FROM sqlite_master
+		WHERE tbl_name = ? AND sql IS NOT NULL
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 0 WHEN 'index' THEN 1 ELSE 2 END, name`
+	rows, err := q.QueryContext(ctx, query, table)
+	if err != nil {
+		return "", fmt.Errorf("error fetching create statement: %v", err)
+	}
+
+	statements, err := scanStrings(rows)
+	if err != nil {
+		return "", err
+	}
+	if len(statements) == 0 {
+		return "", fmt.Errorf("no create statement for %s", table)
+	}
+
+	return strings.Join(statements, ";\n\n") + ";", nil
+}
+
+// EstimateRowCount counts rows exactly; SQLite keeps no cheap statistics by default
+func (d SQLite) EstimateRowCount(ctx context.Context, q querier, table string) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", d.QuoteIdentifier(table))
+	if err := q.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error estimating row count: %v", err)
+	}
+
+	return count, nil
+}
+
+// QuoteIdentifier wraps a name in double quotes, escaping embedded quotes
+func (SQLite) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Placeholder returns the positional ? marker
+func (SQLite) Placeholder(n int) string {
+	return "?"
+}
+
+// Paginate appends LIMIT and OFFSET
+func (SQLite) Paginate(query string, offset, limit int) string {
+	return query + fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+}
+
+// ConnectionID has no meaning for an embedded database
+func (SQLite) ConnectionID(ctx context.Context, conn *sql.Conn) (int64, error) {
+	return 0, nil
+}
+
+// CancelQuery is a no-op: the driver interrupts the statement itself when the context ends
+func (SQLite) CancelQuery(ctx context.Context, db *sql.DB, id int64) error {
+	return nil
+}
+
+// sqliteDefault unquotes string literal defaults; other expressions are returned as is
+func sqliteDefault(expr string) string {
+	if len(expr) >= 2 && strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'") {
+		return strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+	}
+	return expr
+}
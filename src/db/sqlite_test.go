@@ -0,0 +1,326 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+// openMemory opens an in-memory SQLite database and runs the schema script on it
+func openMemory(t *testing.T, schema string) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	// Every connection to :memory: opens a database of its own
+	conn.SetMaxOpenConns(1)
+
+	if schema != "" {
+		if _, err := conn.Exec(schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return conn
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.DBConfig
+		want string
+	}{
+		{"file", config.DBConfig{Database: "app.db"}, "file:app.db?mode=rw&_foreign_keys=on&_busy_timeout=5000"},
+		{"read-only", config.DBConfig{Database: "/data/app.db", ReadOnly: true}, "file:/data/app.db?mode=ro&_foreign_keys=on&_busy_timeout=5000"},
+		{"special characters", config.DBConfig{Database: "a?b#c%.db"}, "file:a%3fb%23c%25.db?mode=rw&_foreign_keys=on&_busy_timeout=5000"},
+		{"params", config.DBConfig{Database: "app.db", Params: map[string]string{"_journal_mode": "WAL"}},
+			"file:app.db?mode=rw&_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"},
+		{"uri as is", config.DBConfig{Database: "file:app.db?cache=shared", ReadOnly: true}, "file:app.db?cache=shared"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SQLite{}.DSN(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteDefault(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"'Pending'", "Pending"},
+		{"'it''s'", "it's"},
+		{"''", ""},
+		{"0", "0"},
+		{"CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"'", "'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			if got := sqliteDefault(tt.expr); got != tt.want {
+				t.Errorf("sqliteDefault(%q) = %q, want %q", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteColumns(t *testing.T) {
+	conn := openMemory(t, `CREATE TABLE items (
+		id INTEGER PRIMARY KEY,
+		sku TEXT NOT NULL UNIQUE,
+		status VARCHAR(20) NOT NULL DEFAULT 'new',
+		note TEXT,
+		qty INT DEFAULT 1,
+		price REAL,
+		total REAL GENERATED ALWAYS AS (qty * price) VIRTUAL
+	)`)
+
+	columns, err := SQLite{}.Columns(context.Background(), conn, "items")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []model.ColumnMetadata{
+		{Name: "id", Type: "integer", Key: "PRI", Extra: "rowid"},
+		{Name: "sku", Type: "text", Key: "UNI"},
+		{Name: "status", Type: "varchar(20)", Default: "new"},
+		{Name: "note", Type: "text", Nullable: true},
+		{Name: "qty", Type: "int", Nullable: true, Default: "1"},
+		{Name: "price", Type: "real", Nullable: true},
+		{Name: "total", Type: "real", Nullable: true, Extra: "VIRTUAL GENERATED"},
+	}
+	if len(columns) != len(want) {
+		t.Fatalf("Columns() returned %d columns, want %d", len(columns), len(want))
+	}
+	for i := range want {
+		t.Run(want[i].Name, func(t *testing.T) {
+			if !reflect.DeepEqual(columns[i], want[i]) {
+				t.Errorf("Columns()[%d] = %+v, want %+v", i, columns[i], want[i])
+			}
+		})
+	}
+}
+
+func TestKeyColumnsSQLite(t *testing.T) {
+	conn := openMemory(t, "")
+
+	tests := []struct {
+		name   string
+		create string
+		want   []string
+	}{
+		{"primary key", "CREATE TABLE t (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE)", []string{"id"}},
+		{"composite primary key", "CREATE TABLE t (a INTEGER, b INTEGER, c TEXT, PRIMARY KEY (a, b))", []string{"a", "b"}},
+		{"unique column", "CREATE TABLE t (email TEXT NOT NULL UNIQUE, name TEXT)", []string{"email"}},
+		{"unique index", "CREATE TABLE t (email TEXT NOT NULL, name TEXT); CREATE UNIQUE INDEX t_email ON t (email)", []string{"email"}},
+		{"nullable unique column", "CREATE TABLE t (email TEXT UNIQUE, name TEXT)", nil},
+		{"composite unique constraint", "CREATE TABLE t (a INTEGER NOT NULL, b INTEGER NOT NULL, UNIQUE (a, b))", nil},
+		{"no key", "CREATE TABLE t (a INTEGER, b TEXT)", nil},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := conn.ExecContext(ctx, "DROP TABLE IF EXISTS t"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := conn.ExecContext(ctx, tt.create); err != nil {
+				t.Fatal(err)
+			}
+
+			columns, err := SQLite{}.Columns(ctx, conn, "t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := KeyColumns(columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteIndices(t *testing.T) {
+	tests := []struct {
+		name   string
+		create string
+		want   []model.IndexMetadata
+	}{
+		{
+			name:   "rowid primary key",
+			create: "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)",
+			want: []model.IndexMetadata{
+				{Name: "PRIMARY", Columns: []model.IndexColumn{{Name: "id"}}, Primary: true, Unique: true, Type: "ROWID", Visible: true, Cardinality: -1},
+			},
+		},
+		{
+			name:   "composite primary key",
+			create: "CREATE TABLE t (a TEXT, b TEXT, PRIMARY KEY (a, b))",
+			want: []model.IndexMetadata{
+				{Name: "sqlite_autoindex_t_1", Columns: []model.IndexColumn{{Name: "a"}, {Name: "b"}}, Primary: true, Unique: true, Type: "BTREE", Visible: true, Cardinality: -1},
+			},
+		},
+		{
+			// PRAGMA index_list reports the newest index first
+			name:   "unique, partial and expression indices",
+			create: "CREATE TABLE t (id INTEGER PRIMARY KEY, email TEXT, name TEXT); CREATE UNIQUE INDEX t_email ON t (email); CREATE INDEX t_named ON t (name) WHERE name IS NOT NULL; CREATE INDEX t_lower ON t (lower(name))",
+			want: []model.IndexMetadata{
+				{Name: "PRIMARY", Columns: []model.IndexColumn{{Name: "id"}}, Primary: true, Unique: true, Type: "ROWID", Visible: true, Cardinality: -1},
+				{Name: "t_lower", Columns: []model.IndexColumn{{Name: "<expression>"}}, Type: "BTREE", Visible: true, Cardinality: -1},
+				{Name: "t_named", Columns: []model.IndexColumn{{Name: "name"}}, Type: "BTREE", Visible: true, Cardinality: -1, Comment: "partial"},
+				{Name: "t_email", Columns: []model.IndexColumn{{Name: "email"}}, Unique: true, Type: "BTREE", Visible: true, Cardinality: -1},
+			},
+		},
+		{"no indices", "CREATE TABLE t (a TEXT)", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openMemory(t, tt.create)
+			got, err := SQLite{}.Indices(context.Background(), conn, "t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Indices() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteForeignKeys(t *testing.T) {
+	conn := openMemory(t, `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, manager_id INTEGER REFERENCES users);
+		CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id) ON DELETE CASCADE);
+		CREATE TABLE items (order_id INTEGER, line INTEGER, PRIMARY KEY (order_id, line));
+		CREATE TABLE notes (order_id INTEGER, line INTEGER, FOREIGN KEY (order_id, line) REFERENCES items);
+	`)
+
+	self := model.ForeignKey{Table: "users", Columns: []string{"manager_id"}, RefTable: "users", RefColumns: []string{"id"},
+		OnUpdate: "NO ACTION", OnDelete: "NO ACTION"}
+	tests := []struct {
+		table string
+		want  []model.ForeignKey
+	}{
+		{"users", []model.ForeignKey{
+			{Table: "orders", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"},
+			self,
+		}},
+		{"items", []model.ForeignKey{
+			{Table: "notes", Columns: []string{"order_id", "line"}, RefTable: "items", RefColumns: []string{"order_id", "line"},
+				OnUpdate: "NO ACTION", OnDelete: "NO ACTION"},
+		}},
+		{"notes", []model.ForeignKey{
+			{Table: "notes", Columns: []string{"order_id", "line"}, RefTable: "items", RefColumns: []string{"order_id", "line"},
+				OnUpdate: "NO ACTION", OnDelete: "NO ACTION"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			got, err := SQLite{}.ForeignKeys(context.Background(), conn, tt.table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForeignKeys(%q) = %+v, want %+v", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteCreateStatement(t *testing.T) {
+	conn := openMemory(t, `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+		CREATE INDEX users_name ON users (name);
+		CREATE VIEW names AS SELECT name FROM users;
+	`)
+
+	tests := []struct {
+		table   string
+		want    string
+		wantErr bool
+	}{
+		{"users", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);\n\nCREATE INDEX users_name ON users (name);", false},
+		{"names", "CREATE VIEW names AS SELECT name FROM users;", false},
+		{"missing", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			got, err := SQLite{}.CreateStatement(context.Background(), conn, tt.table)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateStatement(%q) error = %v, wantErr %v", tt.table, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CreateStatement(%q) = %q, want %q", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteTables(t *testing.T) {
+	conn := openMemory(t, `
+		CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);
+		CREATE TABLE orders (id INTEGER PRIMARY KEY);
+		CREATE VIEW active_users AS SELECT * FROM users;
+	`)
+
+	got, err := SQLite{}.Tables(context.Background(), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sqlite_sequence, created for AUTOINCREMENT, is left out
+	want := []model.TableInfo{
+		{Name: "active_users", Type: model.View},
+		{Name: "orders", Type: model.BaseTable},
+		{Name: "users", Type: model.BaseTable},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tables() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSQLiteObjects(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []model.SchemaObject
+	}{
+		{"no triggers", "CREATE TABLE users (id INTEGER PRIMARY KEY)", nil},
+		{
+			name: "triggers",
+			schema: `CREATE TABLE users (id INTEGER PRIMARY KEY, updated TEXT);
+				CREATE TRIGGER users_touch AFTER UPDATE ON users BEGIN UPDATE users SET updated = 'now' WHERE id = NEW.id; END;`,
+			want: []model.SchemaObject{{Kind: model.TriggerObject, Name: "users_touch", Detail: "ON users",
+				Definition: "CREATE TRIGGER users_touch AFTER UPDATE ON users BEGIN UPDATE users SET updated = 'now' WHERE id = NEW.id; END"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openMemory(t, tt.schema)
+			got, err := SQLite{}.Objects(context.Background(), conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Objects() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
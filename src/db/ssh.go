@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DialFunc opens the network connection to the database server
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// sshTunnel is an SSH connection shared by the managers of the databases reached
+// through it. It is closed when the last of them releases it.
+type sshTunnel struct {
+	client *ssh.Client
+	refs   atomic.Int32
+}
+
+// newSSHTunnel wraps a connected client, holding one reference to it
+func newSSHTunnel(client *ssh.Client) *sshTunnel {
+	t := &sshTunnel{client: client}
+	t.refs.Store(1)
+	return t
+}
+
+// acquire adds a reference to the tunnel, which may be nil
+func (t *sshTunnel) acquire() *sshTunnel {
+	if t != nil {
+		t.refs.Add(1)
+	}
+	return t
+}
+
+// release drops a reference, closing the connection with the last one
+func (t *sshTunnel) release() {
+	if t != nil && t.refs.Add(-1) == 0 {
+		t.client.Close()
+	}
+}
+
+// openSSHTunnel connects to the bastion host. Authentication tries the key file first
+// and then the keys of a running SSH agent; the host key must be in known_hosts.
+func openSSHTunnel(ctx context.Context, settings config.SSHConfig) (*ssh.Client, error) {
+	var auth []ssh.AuthMethod
+
+	if settings.KeyFile != "" {
+		pem, err := os.ReadFile(settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading SSH key: %v", err)
+		}
+
+		var signer ssh.Signer
+		if settings.KeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, []byte(settings.KeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(pem)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing SSH key %s: %v", settings.KeyFile, err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			// The agent connection lives as long as the process; it is cheap
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("no SSH credentials: set a key file or start an SSH agent")
+	}
+
+	hostKeyCallback, err := knownhosts.New(settings.KnownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("error reading known hosts: %v", err)
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            settings.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}
+
+	addr := net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to SSH host %s: %v", addr, err)
+	}
+
+	// The handshake does not watch ctx, so bound it through the connection deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error opening SSH session to %s: %v", addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(clientConn, chans, reqs), nil
+}
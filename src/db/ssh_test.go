@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestOpenSSHTunnel(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := write("id_ed25519", pem.EncodeToMemory(block))
+	block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedKeyFile := write("id_encrypted", pem.EncodeToMemory(block))
+	knownHosts := write("known_hosts", nil)
+
+	// A port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	// Leave the SSH agent out, so that only the key file counts
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	base := config.SSHConfig{Host: "127.0.0.1", Port: port, User: "me", KeyFile: keyFile, KnownHosts: knownHosts}
+	with := func(change func(settings *config.SSHConfig)) config.SSHConfig {
+		settings := base
+		change(&settings)
+		return settings
+	}
+
+	tests := []struct {
+		name     string
+		settings config.SSHConfig
+		wantErr  string
+	}{
+		{"no credentials", with(func(s *config.SSHConfig) { s.KeyFile = "" }), "no SSH credentials"},
+		{"missing key file", with(func(s *config.SSHConfig) { s.KeyFile = filepath.Join(dir, "missing") }), "error reading SSH key"},
+		{"not a key", with(func(s *config.SSHConfig) { s.KeyFile = knownHosts }), "error parsing SSH key"},
+		{"encrypted key without passphrase", with(func(s *config.SSHConfig) { s.KeyFile = encryptedKeyFile }), "error parsing SSH key"},
+		{"wrong passphrase", with(func(s *config.SSHConfig) { s.KeyFile, s.KeyPassphrase = encryptedKeyFile, "wrong" }), "error parsing SSH key"},
+		{"missing known hosts", with(func(s *config.SSHConfig) { s.KnownHosts = filepath.Join(dir, "missing") }), "error reading known hosts"},
+		{"host unreachable", base, "error connecting to SSH host 127.0.0.1:" + strconv.Itoa(port)},
+		{"encrypted key, host unreachable", with(func(s *config.SSHConfig) { s.KeyFile, s.KeyPassphrase = encryptedKeyFile, "secret" }),
+			"error connecting to SSH host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := openSSHTunnel(context.Background(), tt.settings)
+			if err == nil {
+				client.Close()
+				t.Fatalf("openSSHTunnel() succeeded, want error %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("openSSHTunnel() error = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeSSHConn stands in for the connection of an SSH client, recording when it is closed
+type fakeSSHConn struct {
+	ssh.Conn
+	closed chan struct{}
+}
+
+func (c *fakeSSHConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func (c *fakeSSHConn) Wait() error {
+	<-c.closed
+	return nil
+}
+
+func TestSSHTunnelRefs(t *testing.T) {
+	tests := []struct {
+		name       string
+		acquires   int
+		releases   int
+		wantClosed bool
+	}{
+		{"held", 0, 0, false},
+		{"released", 0, 1, true},
+		{"shared", 1, 1, false},
+		{"released by all", 2, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeSSHConn{closed: make(chan struct{})}
+			chans, reqs := make(chan ssh.NewChannel), make(chan *ssh.Request)
+			close(chans)
+			close(reqs)
+
+			tunnel := newSSHTunnel(ssh.NewClient(conn, chans, reqs))
+			for i := 0; i < tt.acquires; i++ {
+				if tunnel.acquire() != tunnel {
+					t.Fatal("acquire() returned another tunnel")
+				}
+			}
+			for i := 0; i < tt.releases; i++ {
+				tunnel.release()
+			}
+
+			select {
+			case <-conn.closed:
+				if !tt.wantClosed {
+					t.Error("tunnel closed while still in use")
+				}
+			default:
+				if tt.wantClosed {
+					t.Error("tunnel left open after the last release")
+				}
+			}
+		})
+	}
+
+	// Managers without a tunnel hold a nil one
+	var none *sshTunnel
+	if none.acquire() != nil {
+		t.Error("acquire() on no tunnel returned one")
+	}
+	none.release()
+}
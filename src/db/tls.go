@@ -0,0 +1,89 @@
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+)
+
+// newTLSConfig builds the client TLS configuration for a mode other than disabled.
+// host is verified unless the settings name another server.
+func newTLSConfig(settings config.TLSConfig, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{ServerName: host}
+	if settings.ServerName != "" {
+		tlsConfig.ServerName = settings.ServerName
+	}
+
+	if settings.CA != "" {
+		pem, err := os.ReadFile(settings.CA)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", settings.CA)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if settings.Cert != "" || settings.Key != "" {
+		if settings.Cert == "" || settings.Key == "" {
+			return nil, fmt.Errorf("a client certificate needs both a cert and a key file")
+		}
+
+		cert, err := tls.LoadX509KeyPair(settings.Cert, settings.Key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch settings.Mode {
+	case config.TLSPreferred, config.TLSRequired:
+		tlsConfig.InsecureSkipVerify = true
+	case config.TLSVerifyCA:
+		// Go verifies the chain and the host name together, so skip its check
+		// and verify only the chain ourselves
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyChain(tlsConfig.RootCAs)
+	case config.TLSVerifyIdentity:
+	default:
+		return nil, fmt.Errorf("unsupported TLS mode: %s", settings.Mode)
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyChain checks the server certificate chain against roots (the system pool when nil)
+// without looking at the host name
+func verifyChain(roots *x509.CertPool) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server presented no certificate")
+		}
+
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("error parsing server certificate: %v", err)
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
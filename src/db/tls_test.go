@@ -0,0 +1,155 @@
+package db
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/md-salehzadeh/dbun/src/config"
+)
+
+// writeCertificate writes a self-signed certificate and its key as PEM files
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"db.example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeCertificate(t, dir)
+	notPEM := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		settings       config.TLSConfig
+		wantServerName string
+		wantSkipVerify bool
+		wantChainCheck bool
+		wantRoots      bool
+		wantClientCert bool
+		wantErr        bool
+	}{
+		{name: "preferred", settings: config.TLSConfig{Mode: config.TLSPreferred}, wantServerName: "db", wantSkipVerify: true},
+		{name: "required", settings: config.TLSConfig{Mode: config.TLSRequired}, wantServerName: "db", wantSkipVerify: true},
+		{name: "verify-ca", settings: config.TLSConfig{Mode: config.TLSVerifyCA, CA: cert},
+			wantServerName: "db", wantSkipVerify: true, wantChainCheck: true, wantRoots: true},
+		{name: "verify-identity", settings: config.TLSConfig{Mode: config.TLSVerifyIdentity, CA: cert, ServerName: "db.example.com"},
+			wantServerName: "db.example.com", wantRoots: true},
+		{name: "client certificate", settings: config.TLSConfig{Mode: config.TLSVerifyIdentity, Cert: cert, Key: key},
+			wantServerName: "db", wantClientCert: true},
+		{name: "cert without key", settings: config.TLSConfig{Mode: config.TLSRequired, Cert: cert}, wantErr: true},
+		{name: "missing CA file", settings: config.TLSConfig{Mode: config.TLSVerifyCA, CA: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "CA file without certificates", settings: config.TLSConfig{Mode: config.TLSVerifyCA, CA: notPEM}, wantErr: true},
+		{name: "key is not a certificate", settings: config.TLSConfig{Mode: config.TLSRequired, Cert: key, Key: key}, wantErr: true},
+		{name: "unknown mode", settings: config.TLSConfig{Mode: "sometimes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTLSConfig(tt.settings, "db")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if got.ServerName != tt.wantServerName {
+				t.Errorf("ServerName = %q, want %q", got.ServerName, tt.wantServerName)
+			}
+			if got.InsecureSkipVerify != tt.wantSkipVerify {
+				t.Errorf("InsecureSkipVerify = %v, want %v", got.InsecureSkipVerify, tt.wantSkipVerify)
+			}
+			if (got.VerifyPeerCertificate != nil) != tt.wantChainCheck {
+				t.Errorf("VerifyPeerCertificate set = %v, want %v", got.VerifyPeerCertificate != nil, tt.wantChainCheck)
+			}
+			if (got.RootCAs != nil) != tt.wantRoots {
+				t.Errorf("RootCAs set = %v, want %v", got.RootCAs != nil, tt.wantRoots)
+			}
+			if (len(got.Certificates) > 0) != tt.wantClientCert {
+				t.Errorf("client certificate set = %v, want %v", len(got.Certificates) > 0, tt.wantClientCert)
+			}
+		})
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	dir := t.TempDir()
+	certFile, _ := writeCertificate(t, dir)
+	other, _ := writeCertificate(t, t.TempDir())
+
+	pool := func(file string) *x509.CertPool {
+		pemData, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(pemData)
+		return roots
+	}
+	serverCert, err := tls.LoadX509KeyPair(certFile, filepath.Join(dir, "key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		roots    *x509.CertPool
+		rawCerts [][]byte
+		wantErr  bool
+	}{
+		{"trusted", pool(certFile), serverCert.Certificate, false},
+		{"untrusted", pool(other), serverCert.Certificate, true},
+		{"no certificate", pool(certFile), nil, true},
+		{"garbage", pool(certFile), [][]byte{[]byte("garbage")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The host name is not checked, so the certificate's name does not matter
+			err := verifyChain(tt.roots)(tt.rawCerts, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,90 +15,302 @@ type TableMetadata struct {
 	Columns []ColumnMetadata
 }
 
+// TableType is the kind of a relation, as reported by information_schema.TABLES
+type TableType string
+
+// Constants for table types
+const (
+	BaseTable    TableType = "BASE TABLE"
+	View         TableType = "VIEW"
+	SystemView   TableType = "SYSTEM VIEW"
+	Sequence     TableType = "SEQUENCE"
+	ForeignTable TableType = "FOREIGN"
+)
+
+// Writable reports whether rows of the relation can be edited from the Data view.
+// Unknown types are assumed to be tables.
+func (t TableType) Writable() bool {
+	return t == BaseTable || t == ""
+}
+
+// Kind returns the sidebar group the relation is listed under
+func (t TableType) Kind() ObjectKind {
+	if t == View || t == SystemView {
+		return ViewObject
+	}
+	return TableObject
+}
+
+// TableInfo names a table or view together with its type
+type TableInfo struct {
+	Name string
+	Type TableType
+}
+
+// ObjectKind is the kind of a schema object listed in the sidebar
+type ObjectKind string
+
+// Constants for object kinds
+const (
+	TableObject     ObjectKind = "TABLE"
+	ViewObject      ObjectKind = "VIEW"
+	ProcedureObject ObjectKind = "PROCEDURE"
+	FunctionObject  ObjectKind = "FUNCTION"
+	TriggerObject   ObjectKind = "TRIGGER"
+	EventObject     ObjectKind = "EVENT"
+)
+
+// ObjectKinds lists the object kinds in the order the sidebar groups them
+var ObjectKinds = []ObjectKind{TableObject, ViewObject, ProcedureObject, FunctionObject, TriggerObject, EventObject}
+
+// Group returns the title of the sidebar group for the kind, e.g. "Procedures"
+func (k ObjectKind) Group() string {
+	name := strings.ToLower(string(k))
+	return strings.ToUpper(name[:1]) + name[1:] + "s"
+}
+
+// RoutineParameter is one parameter of a stored procedure or function
+type RoutineParameter struct {
+	Mode string // IN, OUT or INOUT; empty when the server does not report it
+	Name string
+	Type string
+}
+
+// String renders the parameter as in a routine signature
+func (p RoutineParameter) String() string {
+	return strings.Join(strings.Fields(p.Mode+" "+p.Name+" "+p.Type), " ")
+}
+
+// SchemaObject describes a stored procedure, function, trigger or event
+type SchemaObject struct {
+	Kind       ObjectKind
+	Name       string
+	Detail     string // Return type, trigger timing and table, or event schedule
+	Parameters []RoutineParameter
+	Definition string // Body or full definition, as the server reports it
+}
+
+// Signature renders the object name with its parameter list for routines
+func (o SchemaObject) Signature() string {
+	if o.Kind != ProcedureObject && o.Kind != FunctionObject {
+		return o.Name
+	}
+	params := make([]string, len(o.Parameters))
+	for i, p := range o.Parameters {
+		params[i] = p.String()
+	}
+	return o.Name + "(" + strings.Join(params, ", ") + ")"
+}
+
 // ColumnMetadata contains metadata about a table column
 type ColumnMetadata struct {
 	Name     string
 	Type     string
 	Nullable bool
 	Key      string
+	Default  interface{} // Column default as reported by the server, nil when NULL
+
+	Extra     string // Attributes such as auto_increment or on update CURRENT_TIMESTAMP
+	Charset   string
+	Collation string
+	Comment   string
+	Generated string // Expression of a generated column, empty for stored values
 }
 
-// RowData represents a generic row of data from any table
-type RowData map[string]interface{}
+// IndexColumn is one column, or expression, of an index in key order
+type IndexColumn struct {
+	Name    string
+	SubPart int // Indexed prefix length, 0 when the whole value is indexed
+}
 
-// ViewMode represents the different viewing modes in the application
-type ViewMode string
+// String renders the column with its prefix length, as in CREATE INDEX
+func (c IndexColumn) String() string {
+	if c.SubPart > 0 {
+		return fmt.Sprintf("%s(%d)", c.Name, c.SubPart)
+	}
+	return c.Name
+}
 
-// Constants for view modes
-const (
-	DataMode      ViewMode = "Data"
-	StructureMode ViewMode = "Structure"
-	IndicesMode   ViewMode = "Indices"
-)
+// IndexMetadata contains metadata about a table index
+type IndexMetadata struct {
+	Name        string
+	Columns     []IndexColumn
+	Primary     bool
+	Unique      bool
+	Type        string // Access method such as BTREE, HASH, FULLTEXT or SPATIAL
+	Visible     bool   // Whether the optimizer may use the index
+	Cardinality int64  // Estimated distinct values, -1 when unknown
+	Comment     string
+}
 
-// Sample data models for fallback data
-type User struct {
-	ID       int
-	Username string
-	Email    string
-	Active   bool
+// Kind classifies the index as PRIMARY, UNIQUE or INDEX
+func (i IndexMetadata) Kind() string {
+	switch {
+	case i.Primary:
+		return "PRIMARY"
+	case i.Unique:
+		return "UNIQUE"
+	}
+	return "INDEX"
 }
 
-type Order struct {
-	ID         int
-	UserID     int
-	TotalPrice float64
-	Status     string
+// ColumnList joins the index columns in key order
+func (i IndexMetadata) ColumnList() string {
+	names := make([]string, len(i.Columns))
+	for j, col := range i.Columns {
+		names[j] = col.String()
+	}
+	return strings.Join(names, ", ")
 }
 
-type Product struct {
-	ID       int
-	Name     string
-	Price    float64
-	Category string
+// ForeignKey describes a foreign key constraint from the columns of Table to the
+// columns of RefTable, paired in order
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string // Referential action such as CASCADE or RESTRICT
+	OnDelete   string
 }
 
-type Category struct {
-	ID   int
-	Name string
-	Slug string
+// SplitForeignKeys separates the keys defined on table from the keys of other tables
+// that reference it. A self-referencing key appears in both.
+func SplitForeignKeys(table string, keys []ForeignKey) (outgoing, incoming []ForeignKey) {
+	for _, fk := range keys {
+		if fk.Table == table {
+			outgoing = append(outgoing, fk)
+		}
+		if fk.RefTable == table {
+			incoming = append(incoming, fk)
+		}
+	}
+	return outgoing, incoming
 }
 
-// GenerateSampleData creates sample data for testing without a database
-func GenerateSampleData() ([]User, []Order, []Product, []Category) {
-	users := []User{
-		{ID: 1, Username: "johndoe", Email: "john@example.com", Active: true},
-		{ID: 2, Username: "janedoe", Email: "jane@example.com", Active: true},
-		{ID: 3, Username: "bobsmith", Email: "bob@example.com", Active: false},
-		{ID: 4, Username: "alicejones", Email: "alice@example.com", Active: true},
-		{ID: 5, Username: "mikebrown", Email: "mike@example.com", Active: true},
+// RowData represents a generic row of data from any table
+type RowData map[string]interface{}
+
+// Same reports whether two RowData values refer to the same underlying row map
+func (r RowData) Same(other RowData) bool {
+	return reflect.ValueOf(r).UnsafePointer() == reflect.ValueOf(other).UnsafePointer()
+}
+
+// RowFilter restricts the rows fetched for the Data view
+type RowFilter struct {
+	Equal RowData // Columns that must hold the given values, as when following a foreign key
+	Where string  // SQL condition typed by the user, with ? marking each of Args in order
+	Args  []interface{}
+}
+
+// IsEmpty reports whether the filter lets every row through
+func (f RowFilter) IsEmpty() bool {
+	return len(f.Equal) == 0 && f.Where == ""
+}
+
+// String describes the filter as a readable condition
+func (f RowFilter) String() string {
+	cols := make([]string, 0, len(f.Equal))
+	for col := range f.Equal {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	conditions := make([]string, len(cols))
+	for i, col := range cols {
+		conditions[i] = fmt.Sprintf("%s = %s", col, FormatValue(f.Equal[col]))
 	}
 
-	orders := []Order{
-		{ID: 101, UserID: 1, TotalPrice: 125.99, Status: "Completed"},
-		{ID: 102, UserID: 2, TotalPrice: 89.50, Status: "Processing"},
-		{ID: 103, UserID: 1, TotalPrice: 45.75, Status: "Shipped"},
-		{ID: 104, UserID: 3, TotalPrice: 210.25, Status: "Pending"},
-		{ID: 105, UserID: 4, TotalPrice: 55.00, Status: "Completed"},
+	if f.Where != "" {
+		var where strings.Builder
+		rest := f.Where
+		for _, arg := range f.Args {
+			before, after, found := strings.Cut(rest, "?")
+			if !found {
+				break
+			}
+			where.WriteString(before + FormatValue(arg))
+			rest = after
+		}
+		conditions = append(conditions, where.String()+rest)
 	}
+	return strings.Join(conditions, " AND ")
+}
 
-	products := []Product{
-		{ID: 201, Name: "Laptop", Price: 999.99, Category: "Electronics"},
-		{ID: 202, Name: "Headphones", Price: 129.99, Category: "Electronics"},
-		{ID: 203, Name: "Coffee Maker", Price: 79.50, Category: "Appliances"},
-		{ID: 204, Name: "Running Shoes", Price: 89.95, Category: "Footwear"},
-		{ID: 205, Name: "Desk Chair", Price: 199.99, Category: "Furniture"},
+// SortColumn is one column of the Data view's ORDER BY
+type SortColumn struct {
+	Column string
+	Desc   bool
+}
+
+// ToggleSort cycles column through ascending, descending and unsorted. With multi
+// the other sort columns are kept, and a newly sorted column sorts after them;
+// otherwise the column becomes the only one sorted on.
+func ToggleSort(sorts []SortColumn, column string, multi bool) []SortColumn {
+	var next []SortColumn
+	found := false
+	for _, s := range sorts {
+		if s.Column == column {
+			found = true
+			if !s.Desc {
+				next = append(next, SortColumn{Column: column, Desc: true})
+			}
+			continue
+		}
+		if multi {
+			next = append(next, s)
+		}
 	}
 
-	categories := []Category{
-		{ID: 301, Name: "Electronics", Slug: "electronics"},
-		{ID: 302, Name: "Appliances", Slug: "appliances"},
-		{ID: 303, Name: "Footwear", Slug: "footwear"},
-		{ID: 304, Name: "Furniture", Slug: "furniture"},
-		{ID: 305, Name: "Books", Slug: "books"},
+	if !found {
+		next = append(next, SortColumn{Column: column})
 	}
+	return next
+}
+
+// ChangeKind identifies the type of a pending data change
+type ChangeKind string
+
+// Constants for change kinds
+const (
+	ChangeUpdate ChangeKind = "UPDATE"
+	ChangeInsert ChangeKind = "INSERT"
+	ChangeDelete ChangeKind = "DELETE"
+)
 
-	return users, orders, products, categories
+// Change represents a single staged modification waiting to be committed
+type Change struct {
+	Kind     ChangeKind
+	Table    string
+	Key      RowData // Original key column values identifying the target row (UPDATE, DELETE)
+	Row      RowData // In-memory row the change applies to; its values are inserted for INSERT
+	Column   string  // Modified column (UPDATE only)
+	OldValue interface{}
+	NewValue interface{}
+}
+
+// ViewMode represents the different viewing modes in the application
+type ViewMode string
+
+// Constants for view modes
+const (
+	DataMode      ViewMode = "Data"
+	StructureMode ViewMode = "Structure"
+	IndicesMode   ViewMode = "Indices"
+	QueryMode     ViewMode = "Query"
+	RelationsMode ViewMode = "Relations"
+	DDLMode       ViewMode = "DDL"
+)
+
+// QueryResult holds the outcome of an arbitrary SQL statement
+type QueryResult struct {
+	Columns      []ColumnMetadata // Type holds the database type name, in lower case
+	Rows         []RowData
+	RawRows      [][]interface{} // Row values as the driver returned them, in column order
+	RowsAffected int64
+	Elapsed      time.Duration
+	IsQuery      bool // Whether the statement returned a result set
 }
 
 // Helper functions for value parsing
@@ -109,6 +322,89 @@ func ParseFloat(s string) (float64, error) {
 	return strconv.ParseFloat(s, 64)
 }
 
+// ValueKind is the kind of value a column holds, as far as editing it is concerned
+type ValueKind int
+
+// Constants for value kinds
+const (
+	KindText ValueKind = iota // Text and every type not listed below, sent as typed
+	KindInteger
+	KindDecimal
+	KindFloat
+	KindBool
+)
+
+// valueKinds maps base type names, without length or modifiers, to their kind
+var valueKinds = map[string]ValueKind{
+	"int": KindInteger, "integer": KindInteger, "tinyint": KindInteger, "smallint": KindInteger,
+	"mediumint": KindInteger, "bigint": KindInteger, "int2": KindInteger, "int4": KindInteger,
+	"int8": KindInteger, "smallserial": KindInteger, "serial": KindInteger, "bigserial": KindInteger,
+	"decimal": KindDecimal, "numeric": KindDecimal,
+	"float": KindFloat, "double": KindFloat, "double precision": KindFloat, "real": KindFloat,
+	"float4": KindFloat, "float8": KindFloat,
+	"bool": KindBool, "boolean": KindBool,
+}
+
+// KindOf classifies a column type such as "int(11) unsigned", "NUMERIC(10,2)" or
+// "double precision" by its exact base name, so that e.g. interval or point are text
+func KindOf(colType string) ValueKind {
+	base := strings.ToLower(colType)
+	if open := strings.IndexByte(base, '('); open >= 0 {
+		rest := ""
+		if end := strings.IndexByte(base[open:], ')'); end >= 0 {
+			rest = base[open+end+1:]
+		}
+		base = base[:open] + " " + rest
+	}
+
+	var words []string
+	for _, word := range strings.Fields(base) {
+		if word != "unsigned" && word != "signed" && word != "zerofill" {
+			words = append(words, word)
+		}
+	}
+	return valueKinds[strings.Join(words, " ")]
+}
+
+// ParseCellValue converts text typed into a cell to the value written to the column.
+// Input that does not fit the column's type is an error rather than NULL, and an
+// empty input is only valid for text columns, where it is the empty string.
+// Decimals are kept as text so that no digits are lost on the way to the server.
+func ParseCellValue(col ColumnMetadata, input string) (interface{}, error) {
+	trimmed := strings.TrimSpace(input)
+
+	switch KindOf(col.Type) {
+	case KindInteger:
+		val, err := ParseInt(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid integer", input)
+		}
+		return val, nil
+	case KindDecimal:
+		if _, err := ParseFloat(trimmed); err != nil {
+			return nil, fmt.Errorf("%q is not a valid number", input)
+		}
+		return trimmed, nil
+	case KindFloat:
+		val, err := ParseFloat(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid number", input)
+		}
+		return val, nil
+	case KindBool:
+		switch strings.ToLower(trimmed) {
+		case "true", "yes", "1":
+			return true, nil
+		case "false", "no", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%q is not a valid boolean, use true or false", input)
+	}
+
+	// Text and all other types are sent as typed, including "NULL" or "null"
+	return input, nil
+}
+
 // Truncates text with ellipsis if it exceeds width
 func TruncateWithEllipsis(text string, width int) string {
 	if len(text) <= width {
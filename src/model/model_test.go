@@ -0,0 +1,211 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCellValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		colType string
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{"int", "int(11)", " 42 ", 42, false},
+		{"negative bigint", "BIGINT", "-7", -7, false},
+		{"int rejects text", "int", "abc", nil, true},
+		{"int rejects fraction", "int", "1.5", nil, true},
+		{"int rejects empty", "int", "", nil, true},
+		{"unsigned int", "int(10) unsigned", "7", 7, false},
+		{"serial", "serial", "3", 3, false},
+		{"interval stays text", "interval", "1 day", "1 day", false},
+		{"point stays text", "point", "(1,2)", "(1,2)", false},
+		{"integer array stays text", "integer[]", "{1,2}", "{1,2}", false},
+		{"decimal kept as text", "decimal(10,4)", " 45.6789 ", "45.6789", false},
+		{"numeric kept as text", "numeric", "12345678901234567890.123", "12345678901234567890.123", false},
+		{"decimal rejects text", "decimal(10,2)", "12,50", nil, true},
+		{"float", "float", "1.25", 1.25, false},
+		{"double precision", "double precision", "-0.5", -0.5, false},
+		{"real rejects text", "real", "x", nil, true},
+		{"bool true", "boolean", "TRUE", true, false},
+		{"bool yes", "bool", "yes", true, false},
+		{"bool zero", "boolean", "0", false, false},
+		{"bool rejects other", "boolean", "maybe", nil, true},
+		{"text as typed", "varchar(255)", " hello ", " hello ", false},
+		{"empty text", "text", "", "", false},
+		{"null text stays text", "text", "NULL", "NULL", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCellValue(ColumnMetadata{Name: "c", Type: tt.colType}, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCellValue(%q, %q) error = %v, wantErr %v", tt.colType, tt.input, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCellValue(%q, %q) = %#v, want %#v", tt.colType, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowFilterString(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter RowFilter
+		want   string
+	}{
+		{"empty", RowFilter{}, ""},
+		{"one column", RowFilter{Equal: RowData{"user_id": 3}}, "user_id = 3"},
+		{"columns in name order", RowFilter{Equal: RowData{"b": "x", "a": nil}}, "a = NULL AND b = x"},
+		{"condition", RowFilter{Where: "`age` >= ? AND `name` LIKE ?", Args: []interface{}{"30", "%son"}}, "`age` >= 30 AND `name` LIKE %son"},
+		{"key and condition", RowFilter{Equal: RowData{"id": 1}, Where: "deleted_at IS NULL"}, "id = 1 AND deleted_at IS NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("RowFilter.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleSort(t *testing.T) {
+	asc := func(col string) SortColumn { return SortColumn{Column: col} }
+	desc := func(col string) SortColumn { return SortColumn{Column: col, Desc: true} }
+
+	tests := []struct {
+		name   string
+		sorts  []SortColumn
+		column string
+		multi  bool
+		want   []SortColumn
+	}{
+		{"unsorted to ascending", nil, "a", false, []SortColumn{asc("a")}},
+		{"ascending to descending", []SortColumn{asc("a")}, "a", false, []SortColumn{desc("a")}},
+		{"descending to unsorted", []SortColumn{desc("a")}, "a", false, nil},
+		{"replaces other columns", []SortColumn{asc("a"), desc("b")}, "c", false, []SortColumn{asc("c")}},
+		{"drops others when toggling", []SortColumn{asc("a"), asc("b")}, "b", false, []SortColumn{desc("b")}},
+		{"multi appends", []SortColumn{asc("a")}, "b", true, []SortColumn{asc("a"), asc("b")}},
+		{"multi keeps position", []SortColumn{asc("a"), asc("b")}, "a", true, []SortColumn{desc("a"), asc("b")}},
+		{"multi removes", []SortColumn{asc("a"), desc("b")}, "b", true, []SortColumn{asc("a")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToggleSort(tt.sorts, tt.column, tt.multi); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToggleSort(%v, %q, %v) = %v, want %v", tt.sorts, tt.column, tt.multi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		index       IndexMetadata
+		wantKind    string
+		wantColumns string
+	}{
+		{"primary", IndexMetadata{Primary: true, Unique: true, Columns: []IndexColumn{{Name: "id"}}}, "PRIMARY", "id"},
+		{"unique", IndexMetadata{Unique: true, Columns: []IndexColumn{{Name: "a"}, {Name: "b"}}}, "UNIQUE", "a, b"},
+		{"prefix", IndexMetadata{Columns: []IndexColumn{{Name: "title", SubPart: 10}, {Name: "id"}}}, "INDEX", "title(10), id"},
+		{"no columns", IndexMetadata{}, "INDEX", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.index.Kind(); got != tt.wantKind {
+				t.Errorf("Kind() = %q, want %q", got, tt.wantKind)
+			}
+			if got := tt.index.ColumnList(); got != tt.wantColumns {
+				t.Errorf("ColumnList() = %q, want %q", got, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestSplitForeignKeys(t *testing.T) {
+	orderUser := ForeignKey{Name: "orders_user", Table: "orders", RefTable: "users"}
+	userManager := ForeignKey{Name: "users_manager", Table: "users", RefTable: "users"}
+	userTeam := ForeignKey{Name: "users_team", Table: "users", RefTable: "teams"}
+
+	tests := []struct {
+		name         string
+		table        string
+		keys         []ForeignKey
+		wantOutgoing []ForeignKey
+		wantIncoming []ForeignKey
+	}{
+		{"none", "users", nil, nil, nil},
+		{"outgoing", "orders", []ForeignKey{orderUser}, []ForeignKey{orderUser}, nil},
+		{"incoming", "users", []ForeignKey{orderUser}, nil, []ForeignKey{orderUser}},
+		{"self reference in both", "users", []ForeignKey{orderUser, userManager, userTeam},
+			[]ForeignKey{userManager, userTeam}, []ForeignKey{orderUser, userManager}},
+		{"unrelated", "products", []ForeignKey{orderUser}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outgoing, incoming := SplitForeignKeys(tt.table, tt.keys)
+			if !reflect.DeepEqual(outgoing, tt.wantOutgoing) {
+				t.Errorf("SplitForeignKeys() outgoing = %v, want %v", outgoing, tt.wantOutgoing)
+			}
+			if !reflect.DeepEqual(incoming, tt.wantIncoming) {
+				t.Errorf("SplitForeignKeys() incoming = %v, want %v", incoming, tt.wantIncoming)
+			}
+		})
+	}
+}
+
+func TestTableType(t *testing.T) {
+	tests := []struct {
+		tableType    TableType
+		wantWritable bool
+		wantKind     ObjectKind
+	}{
+		{BaseTable, true, TableObject},
+		{"", true, TableObject},
+		{View, false, ViewObject},
+		{SystemView, false, ViewObject},
+		{Sequence, false, TableObject},
+		{ForeignTable, false, TableObject},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.tableType), func(t *testing.T) {
+			if got := tt.tableType.Writable(); got != tt.wantWritable {
+				t.Errorf("Writable() = %v, want %v", got, tt.wantWritable)
+			}
+			if got := tt.tableType.Kind(); got != tt.wantKind {
+				t.Errorf("Kind() = %q, want %q", got, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestSchemaObjectSignature(t *testing.T) {
+	tests := []struct {
+		name   string
+		object SchemaObject
+		want   string
+	}{
+		{"procedure", SchemaObject{Kind: ProcedureObject, Name: "archive", Parameters: []RoutineParameter{
+			{Mode: "IN", Name: "before", Type: "date"}, {Mode: "OUT", Name: "moved", Type: "int"}}}, "archive(IN before date, OUT moved int)"},
+		{"function without modes", SchemaObject{Kind: FunctionObject, Name: "total", Parameters: []RoutineParameter{{Name: "id", Type: "int"}}}, "total(id int)"},
+		{"unnamed parameter", SchemaObject{Kind: FunctionObject, Name: "f", Parameters: []RoutineParameter{{Type: "text"}}}, "f(text)"},
+		{"no parameters", SchemaObject{Kind: FunctionObject, Name: "now"}, "now()"},
+		{"trigger", SchemaObject{Kind: TriggerObject, Name: "touch", Parameters: []RoutineParameter{{Name: "x"}}}, "touch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.object.Signature(); got != tt.want {
+				t.Errorf("Signature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
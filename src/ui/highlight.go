@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sqlKeywords are the words highlighted as keywords in SQL text
+var sqlKeywords = map[string]bool{
+	"ACTION": true, "ADD": true, "ALGORITHM": true, "ALTER": true, "ALWAYS": true,
+	"AND": true, "AS": true, "ASC": true, "AUTO_INCREMENT": true, "BEFORE": true,
+	"AFTER": true, "BEGIN": true, "BETWEEN": true, "BY": true, "CASCADE": true,
+	"CASE": true, "CHARACTER": true, "CHARSET": true, "CHECK": true, "COLLATE": true,
+	"COMMENT": true, "CONSTRAINT": true, "CREATE": true, "CURRENT_TIMESTAMP": true,
+	"DEFAULT": true, "DEFINER": true, "DELETE": true, "DESC": true, "DISTINCT": true,
+	"EACH": true, "ELSE": true, "END": true, "ENGINE": true, "EXISTS": true,
+	"FOR": true, "FOREIGN": true, "FROM": true, "FULLTEXT": true, "FUNCTION": true,
+	"GENERATED": true, "GROUP": true, "HAVING": true, "IDENTITY": true, "IF": true,
+	"IN": true, "INDEX": true, "INNER": true, "INSERT": true, "INTO": true,
+	"IS": true, "JOIN": true, "KEY": true, "LEFT": true, "LIKE": true,
+	"LIMIT": true, "MATERIALIZED": true, "NO": true, "NOT": true, "NULL": true,
+	"ON": true, "OR": true, "ORDER": true, "OUTER": true, "PRIMARY": true,
+	"PROCEDURE": true, "REFERENCES": true, "REPLACE": true, "RESTRICT": true, "RETURNS": true,
+	"RIGHT": true, "ROW": true, "SECURITY": true, "SELECT": true, "SET": true,
+	"SPATIAL": true, "SQL": true, "STORED": true, "TABLE": true, "TEMPORARY": true,
+	"THEN": true, "TRIGGER": true, "UNION": true, "UNIQUE": true, "UNSIGNED": true,
+	"UPDATE": true, "USING": true, "VALUES": true, "VIEW": true, "VIRTUAL": true,
+	"WHEN": true, "WHERE": true, "WITH": true, "WITHOUT": true, "ZEROFILL": true,
+}
+
+// HighlightSQL colors the keywords, literals, quoted identifiers and comments of a
+// single line of SQL. Tokens never span lines, so text can be highlighted line by line.
+func HighlightSQL(line string) string {
+	keywordStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF9AF0"))
+	stringStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAFFAA"))
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFCCAA"))
+	identifierStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AACCFF"))
+	commentStyle := lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color("#999999"))
+
+	var sb strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-', r == '#':
+			// Comments run to the end of the line
+			sb.WriteString(commentStyle.Render(string(runes[i:])))
+			return sb.String()
+
+		case r == '\'' || r == '"' || r == '`':
+			end := closingQuote(runes, i)
+			token := string(runes[i:end])
+			if r == '\'' {
+				sb.WriteString(stringStyle.Render(token))
+			} else {
+				sb.WriteString(identifierStyle.Render(token))
+			}
+			i = end
+
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			sb.WriteString(numberStyle.Render(string(runes[i:end])))
+			i = end
+
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '$') {
+				end++
+			}
+			word := string(runes[i:end])
+			if sqlKeywords[strings.ToUpper(word)] {
+				sb.WriteString(keywordStyle.Render(word))
+			} else {
+				sb.WriteString(word)
+			}
+			i = end
+
+		default:
+			sb.WriteRune(r)
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+// closingQuote returns the index just past the quote that closes the one at start,
+// treating doubled quotes and backslash escapes as part of the token
+func closingQuote(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && quote == '\'':
+			i++
+		case runes[i] == quote:
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(runes)
+}
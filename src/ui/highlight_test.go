@@ -0,0 +1,47 @@
+package ui
+
+import "testing"
+
+func TestClosingQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		start int
+		want  int
+	}{
+		{"string", "'abc' x", 0, 5},
+		{"doubled quote", "'it''s' x", 0, 7},
+		{"backslash escape", `'a\'b' x`, 0, 6},
+		{"backslash in identifier", "`a\\` x", 0, 4},
+		{"identifier", `x = "a b"`, 4, 9},
+		{"unterminated", "'abc", 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := closingQuote([]rune(tt.line), tt.start); got != tt.want {
+				t.Errorf("closingQuote(%q, %d) = %d, want %d", tt.line, tt.start, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightSQLKeepsText(t *testing.T) {
+	// Without a color terminal the styles render nothing, so only the text is left
+	lines := []string{
+		"CREATE TABLE `users` (",
+		"  `id` int(11) NOT NULL AUTO_INCREMENT, -- the key",
+		"  name varchar(50) DEFAULT 'it''s',",
+		"  price DECIMAL(10,2) # comment 'unterminated",
+		`  "quoted ""identifier""" text`,
+		"",
+	}
+
+	for _, line := range lines {
+		t.Run(line, func(t *testing.T) {
+			if got := HighlightSQL(line); got != line {
+				t.Errorf("HighlightSQL(%q) = %q", line, got)
+			}
+		})
+	}
+}
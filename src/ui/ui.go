@@ -3,11 +3,31 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/md-salehzadeh/dbun/src/model"
 )
 
+// CellMark flags a table cell for special highlighting
+type CellMark int
+
+// Constants for cell marks
+const (
+	MarkNone CellMark = iota
+	MarkModified
+	MarkInserted
+	MarkDeleted
+)
+
+// spinnerFrames are the animation frames shown next to tables being loaded
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner returns the spinner glyph for the given animation frame
+func Spinner(frame int) string {
+	return spinnerFrames[frame%len(spinnerFrames)]
+}
+
 // Styles holds all the styling for the application
 type Styles struct {
 	// Border colors
@@ -28,6 +48,9 @@ type Styles struct {
 	DataTabStyle      lipgloss.Style
 	StructureTabStyle lipgloss.Style
 	IndicesTabStyle   lipgloss.Style
+	RelationsTabStyle lipgloss.Style
+	DDLTabStyle       lipgloss.Style
+	QueryTabStyle     lipgloss.Style
 
 	// Table styles
 	HeaderStyle       lipgloss.Style
@@ -35,6 +58,10 @@ type Styles struct {
 	AltRowStyle       lipgloss.Style
 	SelectedCellStyle lipgloss.Style
 	EditingCellStyle  lipgloss.Style
+	ModifiedCellStyle lipgloss.Style
+	InsertedCellStyle lipgloss.Style
+	DeletedCellStyle  lipgloss.Style
+	SearchMatchStyle  lipgloss.Style
 	RowNumStyle       lipgloss.Style
 	TableBorders      lipgloss.Border
 
@@ -63,7 +90,7 @@ func NewStyles(width, height int) Styles {
 		mainBoxWidth = 20
 	}
 
-	buttonWidth := int(float64(width-6) / 3.0)
+	buttonWidth := int(float64(width-6) / float64(len(viewModes)))
 
 	// Define colors
 	activeBorderColor := lipgloss.Color("#FF00FF")   // Magenta
@@ -153,6 +180,29 @@ func NewStyles(width, height int) Styles {
 			Padding(0, 1).
 			Align(lipgloss.Left),
 
+		ModifiedCellStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#FFB347")).
+			Padding(0, 1).
+			Align(lipgloss.Left),
+
+		InsertedCellStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#77DD77")).
+			Padding(0, 1).
+			Align(lipgloss.Left),
+
+		DeletedCellStyle: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF6961")).
+			Strikethrough(true).
+			Padding(0, 1).
+			Align(lipgloss.Left),
+
+		SearchMatchStyle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#000000")).
+			Background(lipgloss.Color("#FFD700")),
+
 		RowNumStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#888888")).
 			Padding(0, 1).
@@ -206,6 +256,9 @@ func (s Styles) UpdateStyles(focusLeft bool, mode model.ViewMode) Styles {
 	newStyles.DataTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
 	newStyles.StructureTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
 	newStyles.IndicesTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
+	newStyles.RelationsTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
+	newStyles.DDLTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
+	newStyles.QueryTabStyle = newStyles.TabStyle.Copy().Foreground(newStyles.InactiveBorderColor)
 
 	switch mode {
 	case model.DataMode:
@@ -220,13 +273,38 @@ func (s Styles) UpdateStyles(focusLeft bool, mode model.ViewMode) Styles {
 		newStyles.IndicesTabStyle = newStyles.TabStyle.Copy().
 			Foreground(newStyles.ActiveBorderColor).
 			Underline(true)
+	case model.RelationsMode:
+		newStyles.RelationsTabStyle = newStyles.TabStyle.Copy().
+			Foreground(newStyles.ActiveBorderColor).
+			Underline(true)
+	case model.DDLMode:
+		newStyles.DDLTabStyle = newStyles.TabStyle.Copy().
+			Foreground(newStyles.ActiveBorderColor).
+			Underline(true)
+	case model.QueryMode:
+		newStyles.QueryTabStyle = newStyles.TabStyle.Copy().
+			Foreground(newStyles.ActiveBorderColor).
+			Underline(true)
 	}
 
 	return newStyles
 }
 
-// RenderTableList renders the list of tables with selection indicators
-func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx int, scrollPosition int, filtering bool, filterText string, totalCount int) string {
+// viewModes lists the view tabs in the order they are shown
+var viewModes = []model.ViewMode{model.DataMode, model.StructureMode, model.IndicesMode, model.RelationsMode, model.DDLMode, model.QueryMode}
+
+// SidebarItem is one row of the object tree in the sidebar: either a group header,
+// or an object shown with its badge and, while it loads, a spinner
+type SidebarItem struct {
+	Name    string
+	Header  bool
+	Badge   string
+	Loading bool
+}
+
+// RenderTableList renders the object tree of the database, grouped by kind, with
+// selection indicators. selectedIdx and activeIdx index items, headers included.
+func RenderTableList(styles Styles, database string, items []SidebarItem, selectedIdx, activeIdx int, scrollPosition int, filtering bool, filterText string, totalCount int, spinnerFrame int) string {
 	// Calculate inner height available for list items
 	// Overhead: TopBorder(1), BottomBorder(1), Title(1), Blank after Title(1), TopScrollIndicator(1/0), BottomScrollIndicator(1/0), Pagination(1/0), Blank before Pagination(1/0)
 	boxInnerHeight := styles.SidebarStyle.GetHeight() - 2 // Account for top/bottom border
@@ -251,10 +329,10 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 		tempMaxItems = 0
 	}
 	tempEndIdx := scrollPosition + tempMaxItems
-	if tempEndIdx < len(tables) {
+	if tempEndIdx < len(items) {
 		estimatedFooterHeight += scrollIndicatorHeight // "↓ More"
 	}
-	if len(tables) > tempMaxItems { // If total items > estimated visible
+	if len(items) > tempMaxItems { // If total items > estimated visible
 		if estimatedFooterHeight > 0 { // Add blank line before pagination if "↓ More" is shown
 			estimatedFooterHeight += 1
 		}
@@ -269,15 +347,15 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 
 	// Calculate which portion of the list to show
 	endIdx := scrollPosition + maxVisibleItems
-	if endIdx > len(tables) {
-		endIdx = len(tables)
+	if endIdx > len(items) {
+		endIdx = len(items)
 	}
 	// Ensure start index is valid
 	if scrollPosition < 0 {
 		scrollPosition = 0
 	}
-	if scrollPosition > len(tables) {
-		scrollPosition = len(tables)
+	if scrollPosition > len(items) {
+		scrollPosition = len(items)
 	} // Can be empty if scrolled past end
 	if endIdx < scrollPosition {
 		endIdx = scrollPosition
@@ -295,7 +373,11 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 		Width(styles.SidebarStyle.GetWidth() - 4). // Account for border padding
 		Align(lipgloss.Center)
 
-	content.WriteString(titleStyle.Render("TABLES"))
+	title := "OBJECTS"
+	if database != "" {
+		title = model.TruncateWithEllipsis(title+" · "+database, styles.SidebarStyle.GetWidth()-4)
+	}
+	content.WriteString(titleStyle.Render(title))
 	content.WriteString("\n") // Blank line after title
 	currentContentHeight += 2
 	
@@ -319,7 +401,7 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 		content.WriteString(filterStyle.Render(filterDisplay))
 		content.WriteString("\n\n") // Two blank lines after filter
 		currentContentHeight += 3   // Filter + 2 blank lines
-	} else if len(filterText) > 0 && len(tables) < totalCount {
+	} else if len(filterText) > 0 && countObjects(items) < totalCount {
 		// Show active filter, but not in editing mode
 		filterStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#AAAAAA")).
@@ -357,13 +439,26 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 			itemStyleWidth = 1
 		}
 
-		if selectedIdx == i && activeTableIdx == i {
+		item := items[i]
+		if item.Header {
+			// Group headers span the cursor column and cannot be selected
+			headerStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#AAAAAA")).
+				Width(itemStyleWidth + 2)
+			content.WriteString(headerStyle.Render(model.TruncateWithEllipsis(item.Name, itemStyleWidth+2)) + "\n")
+			currentContentHeight += 1
+			numItemsRendered++
+			continue
+		}
+
+		if selectedIdx == i && activeIdx == i {
 			cursor = "●" // Active and selected
 			lineStyle = styles.ActiveItemStyle.Copy().Bold(true).Width(itemStyleWidth)
 		} else if selectedIdx == i {
 			cursor = ">" // Just selected
 			lineStyle = styles.SelectedItemStyle.Copy().Bold(true).Width(itemStyleWidth)
-		} else if activeTableIdx == i {
+		} else if activeIdx == i {
 			cursor = " " // Just active (can happen if selection moved away?) - Use ActiveItemStyle
 			lineStyle = styles.ActiveItemStyle.Copy().Width(itemStyleWidth)
 		} else {
@@ -372,7 +467,7 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 		}
 
 		// Create a fixed-width table name
-		tableName := tables[i]
+		tableName := item.Name
 		// Truncate based on the style's width calculation
 		// Subtract cursor width (1) and space (1)
 		maxTextWidth := itemStyleWidth - 2
@@ -380,8 +475,18 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 			maxTextWidth = 0
 		}
 		
+		// Views and other non-table objects carry a badge after the name
+		suffix := ""
+		if item.Badge != "" {
+			suffix = " " + item.Badge
+		}
+		if item.Loading {
+			// Leave room for the spinner after the name
+			suffix += " " + Spinner(spinnerFrame)
+		}
+
 		// Create a fixed-width table name - no highlighting
-		tableNameDisplay := model.TruncateWithEllipsis(tableName, maxTextWidth)
+		tableNameDisplay := model.TruncateWithEllipsis(tableName, max(0, maxTextWidth-len([]rune(suffix)))) + suffix
 
 		// Render the line
 		content.WriteString(fmt.Sprintf("%s %s\n", cursor, lineStyle.Render(tableNameDisplay)))
@@ -394,7 +499,7 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 	footerHeight := 0
 
 	// Add scroll indicator if there are more items below
-	showMoreIndicator := endIdx < len(tables)
+	showMoreIndicator := endIdx < len(items)
 	if showMoreIndicator {
 		indicatorStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#AAAAAA")).
@@ -407,8 +512,8 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 
 	// Add pagination info
 	// Show pagination if total items > number actually rendered OR if we are scrolled
-	showPagination := len(tables) > numItemsRendered || scrollPosition > 0
-	if showPagination && len(tables) > 0 { // Also check if there are any tables
+	showPagination := len(items) > numItemsRendered || scrollPosition > 0
+	if showPagination && len(items) > 0 { // Also check if there are any items
 		paginationStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#999999")).
 			Align(lipgloss.Center).
@@ -416,8 +521,8 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 
 		paginationText := fmt.Sprintf("%d-%d of %d",
 			scrollPosition+1,
-			min(scrollPosition+numItemsRendered, len(tables)), // Use actual rendered count
-			len(tables))
+			min(scrollPosition+numItemsRendered, len(items)), // Use actual rendered count
+			len(items))
 
 		// Add blank line before pagination only if "↓ More" indicator is also shown
 		if showMoreIndicator {
@@ -454,8 +559,36 @@ func RenderTableList(styles Styles, tables []string, selectedIdx, activeTableIdx
 	return styles.SidebarStyle.Render(finalContentStr)
 }
 
+// countObjects counts the items that are not group headers
+func countObjects(items []SidebarItem) int {
+	count := 0
+	for _, item := range items {
+		if !item.Header {
+			count++
+		}
+	}
+	return count
+}
+
+// TableBadge returns the short marker shown next to objects that are not base tables
+func TableBadge(t model.TableType) string {
+	switch t {
+	case model.BaseTable, "":
+		return ""
+	case model.View:
+		return "[V]"
+	case model.SystemView:
+		return "[SV]"
+	case model.Sequence:
+		return "[S]"
+	case model.ForeignTable:
+		return "[F]"
+	}
+	return "[?]"
+}
+
 // RenderStatusBar renders the application status bar
-func RenderStatusBar(styles Styles, width int, filtering bool, filterCount int, totalCount int) string {
+func RenderStatusBar(styles Styles, width int, filtering bool, filterCount int, totalCount int, message string) string {
 	w := lipgloss.Width
 
 	statusKey := styles.StatusStyle.Render("STATUS")
@@ -465,10 +598,13 @@ func RenderStatusBar(styles Styles, width int, filtering bool, filterCount int,
 	statusText := "Ravishing"
 	// Show filter info even when not in filtering mode (as long as filter is applied)
 	if filtering {
-		statusText = fmt.Sprintf("Filtering... %d/%d tables shown", filterCount, totalCount)
+		statusText = fmt.Sprintf("Filtering... %d/%d objects shown", filterCount, totalCount)
+	} else if message != "" {
+		// Feedback from the last action takes precedence over the filter summary
+		statusText = message
 	} else if filterCount < totalCount {
 		// Also show filter applied state when not actively filtering
-		statusText = fmt.Sprintf("Filtered: %d/%d tables shown", filterCount, totalCount)
+		statusText = fmt.Sprintf("Filtered: %d/%d objects shown", filterCount, totalCount)
 	}
 	
 	statusVal := styles.StatusTextStyle.
@@ -573,8 +709,10 @@ func CalculateDynamicWidths(availableWidth, numColumns int, minWidths, idealWidt
 
 // RenderTable renders a data table with headers and rows
 func RenderTable(styles Styles, mainBoxWidth int,
-	headers []string, rows [][]string,
+	headers []string, sorts []model.SortColumn, rows [][]string,
 	minColWidths, idealColWidths []int,
+	marks [][]CellMark,
+	matches [][][]int,
 	cursorRow, cursorCol int,
 	focusLeft, editing bool,
 	editBuffer string,
@@ -592,7 +730,8 @@ func RenderTable(styles Styles, mainBoxWidth int,
 		// We still need to maintain the width for visual alignment with data cells,
 		// but we'll ensure the header text is not truncated by showing the full text
 		// and letting it overflow within its cell
-		maxWidth := max(len(header)+2, colWidths[i]) // +2 for padding
+		header += SortIndicator(sorts, header)
+		maxWidth := max(len([]rune(header))+2, colWidths[i]) // +2 for padding
 		headerCells[i] = styles.HeaderStyle.Copy().Width(maxWidth).Render(header)
 	}
 
@@ -619,6 +758,16 @@ func RenderTable(styles Styles, mainBoxWidth int,
 
 			// Apply appropriate style based on selection/editing state
 			styleToUse := rowStyle
+			if i < len(marks) && j < len(marks[i]) {
+				switch marks[i][j] {
+				case MarkModified:
+					styleToUse = styles.ModifiedCellStyle
+				case MarkInserted:
+					styleToUse = styles.InsertedCellStyle
+				case MarkDeleted:
+					styleToUse = styles.DeletedCellStyle
+				}
+			}
 			// Compare with relative cursorRow (adjustedCursorRow passed to this function)
 			if !focusLeft && cursorRow == i && cursorCol == j {
 				if editing {
@@ -630,6 +779,14 @@ func RenderTable(styles Styles, mainBoxWidth int,
 					styleToUse = styles.SelectedCellStyle
 				}
 			}
+			if i < len(matches) && j < len(matches[i]) && len(matches[i][j]) > 0 {
+				// Highlight the characters matched by the search
+				visible := len(cellContent)
+				if cellContent != cell && colWidths[j] > 3 {
+					visible -= 3 // Leave the ellipsis plain
+				}
+				cellContent = highlightMatches(cellContent, matches[i][j], visible, styleToUse, styles.SearchMatchStyle)
+			}
 			cells[j] = styleToUse.Copy().Width(colWidths[j]).Render(cellContent)
 		}
 
@@ -659,11 +816,66 @@ func RenderTable(styles Styles, mainBoxWidth int,
 	return sb.String()
 }
 
+// highlightMatches renders text with the bytes at positions, below limit, in the
+// match style and the rest in the cell's style, so the cell background survives
+// the highlight
+func highlightMatches(text string, positions []int, limit int, cellStyle, matchStyle lipgloss.Style) string {
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		if pos < limit {
+			matched[pos] = true
+		}
+	}
+	plain := cellStyle.Copy().UnsetPadding().UnsetWidth()
+
+	var sb strings.Builder
+	start := 0
+	for i := range text {
+		if !matched[i] {
+			continue
+		}
+		if start < i {
+			sb.WriteString(plain.Render(text[start:i]))
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		sb.WriteString(matchStyle.Render(text[i : i+size]))
+		start = i + size
+	}
+	if start < len(text) {
+		sb.WriteString(plain.Render(text[start:]))
+	}
+	return sb.String()
+}
+
+// SortIndicator returns the arrow shown after a sorted column's header, numbered by
+// priority when several columns are sorted on, or an empty string
+func SortIndicator(sorts []model.SortColumn, column string) string {
+	for i, s := range sorts {
+		if s.Column != column {
+			continue
+		}
+		arrow := "↑"
+		if s.Desc {
+			arrow = "↓"
+		}
+		if len(sorts) > 1 {
+			return fmt.Sprintf(" %s%d", arrow, i+1)
+		}
+		return " " + arrow
+	}
+	return ""
+}
+
 // RenderTableData formats table data into a displayable format with scrolling
 func RenderTableData(styles Styles, mainBoxWidth int,
 	tableName string,
+	filter string,
+	sorts []model.SortColumn,
 	metadata []model.ColumnMetadata,
 	data []model.RowData,
+	marks map[int]map[string]CellMark,
+	matches map[int]map[string][]int,
+	rowOffset int, rowEstimate int64, hasMore bool,
 	cursorRow, cursorCol int,
 	focusLeft, editing bool,
 	editBuffer string,
@@ -690,7 +902,12 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 		Align(lipgloss.Center).
 		Width(mainBoxWidth - 4) // Account for MainBoxStyle padding
 
-	titleContent := titleStyle.Render(tableName) + "\n\n" // Title + 2 blank lines
+	// An active row filter is shown next to the table name
+	title := tableName
+	if filter != "" {
+		title = model.TruncateWithEllipsis(tableName+"  WHERE "+filter, max(mainBoxWidth-6, 0))
+	}
+	titleContent := titleStyle.Render(title) + "\n\n" // Title + 2 blank lines
 	currentContentHeight := 3
 
 	// --- Handle No Metadata or No Data ---
@@ -717,7 +934,7 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 	minColWidths := make([]int, len(headers))
 	idealColWidths := make([]int, len(headers))
 	for i, col := range metadata {
-		headerLen := len(col.Name)
+		headerLen := len(col.Name) + len([]rune(SortIndicator(sorts, col.Name)))
 		minColWidths[i] = max(3, headerLen) // Min width is 3 or header length
 
 		// Ideal width logic (simplified)
@@ -740,9 +957,9 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 	if len(data) == 0 {
 		// Render empty table (just header) + "No data" message
 		emptyRows := [][]string{}
-		tableContent := RenderTable(styles, mainBoxWidth-4, headers, emptyRows,
+		tableContent := RenderTable(styles, mainBoxWidth-4, headers, sorts, emptyRows,
 			minColWidths, idealColWidths,
-			-1, -1, focusLeft, false, "",
+			nil, nil, -1, -1, focusLeft, false, "",
 			0, // Pass 0 for scrollPosition when no data
 		)
 		noDataStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Align(lipgloss.Center).Width(mainBoxWidth - 4)
@@ -787,8 +1004,14 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 
 	// --- Prepare Data Rows ---
 	rows := make([][]string, numVisibleRows)
+	visibleMarks := make([][]CellMark, numVisibleRows)
+	visibleMatches := make([][][]int, numVisibleRows)
 	for i, rowData := range visibleData {
 		rows[i] = make([]string, len(headers))
+		visibleMarks[i] = make([]CellMark, len(headers))
+		visibleMatches[i] = make([][]int, len(headers))
+		rowMarks := marks[scrollPosition+i]
+		rowMatches := matches[scrollPosition+i]
 		for j, col := range metadata {
 			colName := col.Name
 			if val, ok := rowData[colName]; ok {
@@ -796,6 +1019,8 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 			} else {
 				rows[i][j] = ""
 			}
+			visibleMarks[i][j] = rowMarks[colName]
+			visibleMatches[i][j] = rowMatches[colName]
 		}
 	}
 
@@ -806,11 +1031,13 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 	}
 
 	// --- Render Table ---
-	tableContent := RenderTable(styles, mainBoxWidth-4, headers, rows,
+	tableContent := RenderTable(styles, mainBoxWidth-4, headers, sorts, rows,
 		minColWidths, idealColWidths,
+		visibleMarks,
+		visibleMatches,
 		adjustedCursorRow, cursorCol,
 		focusLeft, editing, editBuffer,
-		scrollPosition, // Pass the actual scrollPosition
+		rowOffset+scrollPosition, // Absolute position of the first visible row
 	)
 	// Table height: Header(1) + Rows(numVisibleRows) + Borders(2) = numVisibleRows + 3
 	tableHeight := numVisibleRows + 3
@@ -825,21 +1052,25 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 		Align(lipgloss.Left)
 
 	totalRows := len(data)
-	currentStart := scrollPosition + 1
-	currentEnd := scrollPosition + numVisibleRows
+	currentStart := rowOffset + scrollPosition + 1
+	currentEnd := rowOffset + scrollPosition + numVisibleRows
 
 	// Show scroll info if needed
-	showScrollInfo := totalRows > maxVisibleRows || scrollPosition > 0
+	showScrollInfo := totalRows > maxVisibleRows || scrollPosition > 0 || rowOffset > 0 || hasMore
 	if showScrollInfo {
 		var indicators []string
-		if scrollPosition > 0 {
+		if scrollPosition > 0 || rowOffset > 0 {
 			indicators = append(indicators, "↑ Prev")
 		}
-		if currentEnd < totalRows {
+		if scrollPosition+numVisibleRows < totalRows || hasMore {
 			indicators = append(indicators, "↓ More")
 		}
 
-		paginationInfo := fmt.Sprintf("Rows %d-%d of %d", currentStart, currentEnd, totalRows)
+		// The row estimate comes from server statistics and is only approximate
+		paginationInfo := fmt.Sprintf("Rows %d-%d of %d", currentStart, currentEnd, rowOffset+totalRows)
+		if hasMore || rowOffset > 0 {
+			paginationInfo = fmt.Sprintf("Rows %d-%d of ~%d", currentStart, currentEnd, max64(rowEstimate, int64(rowOffset+totalRows)))
+		}
 
 		scrollInfo := paginationInfo
 		if len(indicators) > 0 {
@@ -876,8 +1107,58 @@ func RenderTableData(styles Styles, mainBoxWidth int,
 	return finalStr
 }
 
-// RenderTableStructure renders a table's structure information with scrolling
-func RenderTableStructure(tableName string, metadata []model.ColumnMetadata, scrollPosition int) string {
+// QueryEditorHeight is the number of lines taken by the query editor and its status line
+const QueryEditorHeight = 9
+
+// RenderQueryEditor renders the multi-line SQL editor of the Query view with a status line below it
+func RenderQueryEditor(styles Styles, mainBoxWidth int, buffer string, editing bool, status string) string {
+	// Border(2) + Status(1) + Blank(1) leave the rest for text lines
+	visibleLines := QueryEditorHeight - 4
+
+	borderColor := styles.InactiveBorderColor
+	if editing {
+		borderColor = styles.ActiveBorderColor
+	}
+
+	editorStyle := lipgloss.NewStyle().
+		Width(mainBoxWidth-6). // Account for MainBoxStyle padding and editor border
+		Height(visibleLines).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Padding(0, 1)
+
+	// Keep the last lines in view since the cursor is always at the end of the buffer
+	lines := strings.Split(buffer, "\n")
+	if editing {
+		lines[len(lines)-1] += "|"
+	}
+	if len(lines) > visibleLines {
+		lines = lines[len(lines)-visibleLines:]
+	}
+	if buffer == "" && !editing {
+		placeholderStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Italic(true)
+		lines = []string{placeholderStyle.Render("Press e or Enter to write a query")}
+	}
+
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+
+	return editorStyle.Render(strings.Join(lines, "\n")) + "\n" + statusStyle.Render(status) + "\n"
+}
+
+// RenderLoading renders a placeholder for a table whose data is still being fetched
+func RenderLoading(mainBoxWidth int, tableName string, spinnerFrame int) string {
+	loadingStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA")).
+		Align(lipgloss.Center).
+		Width(mainBoxWidth - 4).
+		PaddingTop(2)
+
+	return loadingStyle.Render(fmt.Sprintf("%s Loading %s...", Spinner(spinnerFrame), tableName))
+}
+
+// RenderTableStructure renders a table's structure information, scrolled by whole
+// lines since a column with details takes two
+func RenderTableStructure(styles Styles, tableName string, metadata []model.ColumnMetadata, scrollPosition int) string {
 	if len(metadata) == 0 {
 		return fmt.Sprintf("No metadata available for table: %s", tableName)
 	}
@@ -914,32 +1195,17 @@ func RenderTableStructure(tableName string, metadata []model.ColumnMetadata, scr
 		Italic(true).
 		Foreground(lipgloss.Color("#AAFFAA"))
 
-	// Calculate visible rows based on reasonable estimate
-	visibleMetadata := metadata
-	maxVisibleRows := 20 // Approximate - could refine based on box height
-
-	if scrollPosition >= 0 && len(metadata) > maxVisibleRows {
-		endPos := scrollPosition + maxVisibleRows
-		if endPos > len(metadata) {
-			endPos = len(metadata)
-		}
+	// Style for defaults and extra attributes
+	defaultStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#DDDDAA"))
 
-		if scrollPosition < len(metadata) {
-			visibleMetadata = metadata[scrollPosition:endPos]
-		} else {
-			visibleMetadata = []model.ColumnMetadata{}
-		}
-	}
+	extraStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFAAAA"))
 
-	// Add scroll indicators with consistent styling
-	if scrollPosition > 0 {
-		indicatorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#AAAAAA")).
-			Align(lipgloss.Center)
-
-		sb.WriteString(indicatorStyle.Render("↑ Previous columns"))
-		sb.WriteString("\n\n")
-	}
+	// Collation, comment and generation expression go on a dimmed line of their own
+	detailStyle := lipgloss.NewStyle().
+		Italic(true).
+		Foreground(lipgloss.Color("#999999"))
 
 	// Structure table header
 	headerStyle := lipgloss.NewStyle().
@@ -947,13 +1213,13 @@ func RenderTableStructure(tableName string, metadata []model.ColumnMetadata, scr
 		Foreground(lipgloss.Color("#FFFFFF")).
 		Background(lipgloss.Color("#333366"))
 
-	sb.WriteString(headerStyle.Render(" Column Name       Type                    Constraints     Key "))
-	sb.WriteString("\n")
-	sb.WriteString(headerStyle.Render("─────────────────────────────────────────────────────────────"))
-	sb.WriteString("\n")
+	header := fmt.Sprintf(" %-20s %-22s %-8s %-3s %-18s %-24s ",
+		"Column Name", "Type", "Null", "Key", "Default", "Extra")
 
-	// Show the column details
-	for i, col := range visibleMetadata {
+	// Render the lines of every column, remembering which column each belongs to
+	var lines []string
+	var owners []int
+	for i, col := range metadata {
 		// Background color for alternating rows
 		rowStyle := lipgloss.NewStyle()
 		if i%2 == 1 {
@@ -970,36 +1236,78 @@ func RenderTableStructure(tableName string, metadata []model.ColumnMetadata, scr
 			keyStr = "-"
 		}
 
+		defaultStr := "-"
+		if col.Default != nil {
+			defaultStr = model.FormatValue(col.Default)
+		} else if col.Nullable && !strings.Contains(col.Extra, "GENERATED") {
+			defaultStr = "NULL"
+		}
+
+		extraStr := col.Extra
+		if extraStr == "" {
+			extraStr = "-"
+		}
+
 		// Format each field with padding to align columns
-		colNameText := rowStyle.Render(" " + colNameStyle.Render(model.TruncateWithEllipsis(col.Name, 16)))
-		typeText := rowStyle.Render(" " + typeStyle.Render(model.TruncateWithEllipsis(col.Type, 22)))
-		nullText := rowStyle.Render(" " + constraintStyle.Render(model.TruncateWithEllipsis(nullableStr, 14)))
-		keyText := rowStyle.Render(" " + keyStyle.Render(model.TruncateWithEllipsis(keyStr, 3)) + " ")
+		colNameText := rowStyle.Render(" " + colNameStyle.Render(fmt.Sprintf("%-20s", model.TruncateWithEllipsis(col.Name, 20))))
+		typeText := rowStyle.Render(" " + typeStyle.Render(fmt.Sprintf("%-22s", model.TruncateWithEllipsis(col.Type, 22))))
+		nullText := rowStyle.Render(" " + constraintStyle.Render(fmt.Sprintf("%-8s", nullableStr)))
+		keyText := rowStyle.Render(" " + keyStyle.Render(fmt.Sprintf("%-3s", model.TruncateWithEllipsis(keyStr, 3))))
+		defaultText := rowStyle.Render(" " + defaultStyle.Render(fmt.Sprintf("%-18s", model.TruncateWithEllipsis(defaultStr, 18))))
+		extraText := rowStyle.Render(" " + extraStyle.Render(fmt.Sprintf("%-24s", model.TruncateWithEllipsis(extraStr, 24))) + " ")
+
+		lines = append(lines, colNameText+typeText+nullText+keyText+defaultText+extraText)
+		owners = append(owners, i)
+
+		if details := columnDetails(col); details != "" {
+			detail := model.TruncateWithEllipsis(details, lipgloss.Width(header)-4)
+			lines = append(lines, "   "+detailStyle.Render(detail))
+			owners = append(owners, i)
+		}
+	}
 
-		sb.WriteString(colNameText + typeText + nullText + keyText)
+	// Overhead: Borders(2), Title(2), header(2), scroll indicators(4), pagination(2)
+	maxVisibleRows := max(1, styles.MainBoxStyle.GetHeight()-12)
+	scrollPosition = max(0, min(scrollPosition, len(lines)-1))
+	endPos := min(scrollPosition+maxVisibleRows, len(lines))
+
+	// Add scroll indicators with consistent styling
+	indicatorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA")).
+		Align(lipgloss.Center)
+
+	if scrollPosition > 0 {
+		sb.WriteString(indicatorStyle.Render("↑ Previous columns"))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString(headerStyle.Render(header))
+	sb.WriteString("\n")
+	sb.WriteString(headerStyle.Render(strings.Repeat("─", lipgloss.Width(header))))
+	sb.WriteString("\n")
+
+	// Show the column details
+	for _, line := range lines[scrollPosition:endPos] {
+		sb.WriteString(line)
 		sb.WriteString("\n")
 	}
 
 	// Add more indicator and pagination info
-	if scrollPosition+len(visibleMetadata) < len(metadata) {
-		indicatorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#AAAAAA")).
-			Align(lipgloss.Center)
-
+	if endPos < len(lines) {
 		sb.WriteString("\n")
 		sb.WriteString(indicatorStyle.Render("↓ More columns"))
 	}
 
 	// Add scroll position indicator with nice styling
-	if len(metadata) > maxVisibleRows {
+	if len(lines) > maxVisibleRows {
 		paginationStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#999999")).
 			Align(lipgloss.Right).
 			PaddingTop(1)
 
 		paginationText := fmt.Sprintf("Columns %d-%d of %d",
-			scrollPosition+1,
-			scrollPosition+len(visibleMetadata),
+			owners[scrollPosition]+1,
+			owners[endPos-1]+1,
 			len(metadata))
 
 		sb.WriteString("\n")
@@ -1009,8 +1317,37 @@ func RenderTableStructure(tableName string, metadata []model.ColumnMetadata, scr
 	return sb.String()
 }
 
+// StructureLines returns how many lines the columns take in Structure mode
+func StructureLines(metadata []model.ColumnMetadata) int {
+	lines := len(metadata)
+	for _, col := range metadata {
+		if columnDetails(col) != "" {
+			lines++
+		}
+	}
+	return lines
+}
+
+// columnDetails joins the generation expression, collation or character set and
+// comment of a column, shown on a line below it
+func columnDetails(col model.ColumnMetadata) string {
+	var details []string
+	if col.Generated != "" {
+		details = append(details, "AS ("+col.Generated+")")
+	}
+	if col.Collation != "" {
+		details = append(details, "collate "+col.Collation)
+	} else if col.Charset != "" {
+		details = append(details, "charset "+col.Charset)
+	}
+	if col.Comment != "" {
+		details = append(details, "-- "+col.Comment)
+	}
+	return strings.Join(details, "  ")
+}
+
 // RenderTableIndices renders a table's indices information with scrolling
-func RenderTableIndices(tableName string, indices []string, scrollPosition int) string {
+func RenderTableIndices(tableName string, indices []model.IndexMetadata, scrollPosition int) string {
 	if len(indices) == 0 {
 		return fmt.Sprintf("No index information available for table: %s", tableName)
 	}
@@ -1042,7 +1379,7 @@ func RenderTableIndices(tableName string, indices []string, scrollPosition int)
 		if scrollPosition < len(indices) {
 			visibleIndices = indices[scrollPosition:endPos]
 		} else {
-			visibleIndices = []string{}
+			visibleIndices = []model.IndexMetadata{}
 		}
 	}
 
@@ -1062,9 +1399,11 @@ func RenderTableIndices(tableName string, indices []string, scrollPosition int)
 		Foreground(lipgloss.Color("#FFFFFF")).
 		Background(lipgloss.Color("#333366"))
 
-	sb.WriteString(headerStyle.Render(" Index Name                       Type        Columns                   "))
+	header := fmt.Sprintf(" %-26s %-7s %-8s %-24s %11s %-3s %-16s ",
+		"Index Name", "Kind", "Type", "Columns", "Cardinality", "Vis", "Comment")
+	sb.WriteString(headerStyle.Render(header))
 	sb.WriteString("\n")
-	sb.WriteString(headerStyle.Render("─────────────────────────────────────────────────────────────────────"))
+	sb.WriteString(headerStyle.Render(strings.Repeat("─", lipgloss.Width(header))))
 	sb.WriteString("\n")
 
 	// Index type and name styling
@@ -1072,12 +1411,21 @@ func RenderTableIndices(tableName string, indices []string, scrollPosition int)
 		Bold(true).
 		Foreground(lipgloss.Color("#AACCFF"))
 
-	indexTypeStyle := lipgloss.NewStyle().
+	indexKindStyle := lipgloss.NewStyle().
 		Italic(true).
 		Foreground(lipgloss.Color("#AAFFAA"))
 
-	// For demo, we'll parse the index string to extract type and involved columns
-	// In a real scenario, you might have more structured index data
+	indexTypeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFAAAA"))
+
+	// Invisible indices are ignored by the optimizer, so they are dimmed
+	hiddenStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#777777"))
+
+	commentStyle := lipgloss.NewStyle().
+		Italic(true).
+		Foreground(lipgloss.Color("#AAAAAA"))
+
 	for i, idx := range visibleIndices {
 		// Background color for alternating rows
 		rowStyle := lipgloss.NewStyle()
@@ -1085,33 +1433,28 @@ func RenderTableIndices(tableName string, indices []string, scrollPosition int)
 			rowStyle = rowStyle.Background(lipgloss.Color("#222233"))
 		}
 
-		// Extract index type (simple demo parsing)
-		indexName := idx
-		indexType := "INDEX"
-
-		if strings.HasPrefix(strings.ToUpper(idx), "PRIMARY") {
-			indexType = "PRIMARY"
-		} else if strings.HasPrefix(strings.ToUpper(idx), "UNIQUE") {
-			indexType = "UNIQUE"
-		} else if strings.HasPrefix(strings.ToUpper(idx), "IDX_") || strings.HasPrefix(idx, "index_") {
-			indexType = "INDEX"
+		nameStyle, kindStyle, typeStyle := indexNameStyle, indexKindStyle, indexTypeStyle
+		visible := "yes"
+		if !idx.Visible {
+			nameStyle, kindStyle, typeStyle = hiddenStyle, hiddenStyle, hiddenStyle
+			visible = "no"
 		}
 
-		// Extract columns (simplified - in real app you would have actual index metadata with columns)
-		columns := "N/A"
-		if strings.Contains(idx, "_") {
-			parts := strings.Split(idx, "_")
-			if len(parts) > 1 {
-				columns = strings.Join(parts[1:], ", ")
-			}
+		cardinality := "-"
+		if idx.Cardinality >= 0 {
+			cardinality = fmt.Sprintf("%d", idx.Cardinality)
 		}
 
 		// Format fields with proper alignment and styling
-		nameText := rowStyle.Render(" " + indexNameStyle.Render(model.TruncateWithEllipsis(indexName, 30)))
-		typeText := rowStyle.Render(" " + indexTypeStyle.Render(model.TruncateWithEllipsis(indexType, 10)))
-		columnsText := rowStyle.Render(" " + model.TruncateWithEllipsis(columns, 25) + " ")
-
-		sb.WriteString(nameText + typeText + columnsText)
+		nameText := rowStyle.Render(" " + nameStyle.Render(fmt.Sprintf("%-26s", model.TruncateWithEllipsis(idx.Name, 26))))
+		kindText := rowStyle.Render(" " + kindStyle.Render(fmt.Sprintf("%-7s", idx.Kind())))
+		typeText := rowStyle.Render(" " + typeStyle.Render(fmt.Sprintf("%-8s", model.TruncateWithEllipsis(idx.Type, 8))))
+		columnsText := rowStyle.Render(" " + fmt.Sprintf("%-24s", model.TruncateWithEllipsis(idx.ColumnList(), 24)))
+		cardinalityText := rowStyle.Render(" " + fmt.Sprintf("%11s", cardinality))
+		visibleText := rowStyle.Render(" " + fmt.Sprintf("%-3s", visible))
+		commentText := rowStyle.Render(" " + commentStyle.Render(fmt.Sprintf("%-16s", model.TruncateWithEllipsis(idx.Comment, 16))) + " ")
+
+		sb.WriteString(nameText + kindText + typeText + columnsText + cardinalityText + visibleText + commentText)
 		sb.WriteString("\n")
 	}
 
@@ -1144,6 +1487,230 @@ func RenderTableIndices(tableName string, indices []string, scrollPosition int)
 	return sb.String()
 }
 
+// RenderTableRelations renders the foreign keys of a table and those referencing it,
+// scrolled by whole lines. Outgoing keys come first in the selection order, followed
+// by incoming ones.
+func RenderTableRelations(styles Styles, tableName string, outgoing, incoming []model.ForeignKey, selectedIdx int, scrollPosition int) string {
+	var sb strings.Builder
+
+	// Add title with consistent styling
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#1E90FF")).
+		Padding(0, 1).
+		Align(lipgloss.Center)
+
+	sb.WriteString(titleStyle.Render(tableName))
+	sb.WriteString("\n\n")
+
+	if len(outgoing) == 0 && len(incoming) == 0 {
+		sb.WriteString(fmt.Sprintf("No foreign keys reference or are defined on table: %s", tableName))
+		return sb.String()
+	}
+
+	sectionStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#333366"))
+
+	tableStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#AACCFF"))
+
+	ruleStyle := lipgloss.NewStyle().
+		Italic(true).
+		Foreground(lipgloss.Color("#999999"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#4444AA"))
+
+	// Each key takes one line; each section adds a header, and a blank line separates them
+	var lines []string
+	render := func(title string, keys []model.ForeignKey, first int, out bool) {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, sectionStyle.Render(fmt.Sprintf(" %s (%d) ", title, len(keys))))
+		if len(keys) == 0 {
+			lines = append(lines, ruleStyle.Render("  none"))
+		}
+		for i, fk := range keys {
+			// The table on the other side of the relation is highlighted, as Enter jumps there
+			from := fk.Table + fmt.Sprintf("(%s)", strings.Join(fk.Columns, ", "))
+			to := tableStyle.Render(fk.RefTable) + fmt.Sprintf("(%s)", strings.Join(fk.RefColumns, ", "))
+			if !out {
+				from = tableStyle.Render(fk.Table) + fmt.Sprintf("(%s)", strings.Join(fk.Columns, ", "))
+				to = fk.RefTable + fmt.Sprintf("(%s)", strings.Join(fk.RefColumns, ", "))
+			}
+
+			name := fk.Name
+			if name == "" {
+				name = "-"
+			}
+			label := fmt.Sprintf(" %s ", model.TruncateWithEllipsis(name, 30))
+			if first+i == selectedIdx {
+				label = selectedStyle.Render(label)
+			}
+
+			rules := ruleStyle.Render(fmt.Sprintf("ON UPDATE %s  ON DELETE %s", fk.OnUpdate, fk.OnDelete))
+			lines = append(lines, fmt.Sprintf(" %s %s → %s  %s", label, from, to, rules))
+		}
+	}
+	render("References", outgoing, 0, true)
+	render("Referenced by", incoming, len(outgoing), false)
+
+	// Overhead: Borders(2), Title(2), scroll indicators(3)
+	maxVisibleRows := max(1, styles.MainBoxStyle.GetHeight()-7)
+	scrollPosition = max(0, min(scrollPosition, len(lines)-maxVisibleRows))
+	endPos := min(scrollPosition+maxVisibleRows, len(lines))
+
+	indicatorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA")).
+		Align(lipgloss.Center)
+
+	if scrollPosition > 0 {
+		sb.WriteString(indicatorStyle.Render("↑ Previous relations"))
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString(strings.Join(lines[scrollPosition:endPos], "\n"))
+
+	if endPos < len(lines) {
+		sb.WriteString("\n")
+		sb.WriteString(indicatorStyle.Render("↓ More relations"))
+	}
+
+	return sb.String()
+}
+
+// RelationsLines returns how many lines the foreign keys take in Relations mode:
+// each section is a header and its keys or "none", with a blank line between them
+func RelationsLines(outgoing, incoming []model.ForeignKey) int {
+	return max(1, len(outgoing)) + max(1, len(incoming)) + 3
+}
+
+// RelationLine returns the line the foreign key at idx is shown on in Relations
+// mode, counting outgoing keys first as RenderTableRelations does
+func RelationLine(outgoing []model.ForeignKey, idx int) int {
+	if idx < len(outgoing) {
+		return 1 + idx
+	}
+	return max(1, len(outgoing)) + 3 + idx - len(outgoing)
+}
+
+// RenderTableDDL renders the create statement of a table with SQL highlighting,
+// scrolled by whole lines
+func RenderTableDDL(styles Styles, tableName string, ddl string, scrollPosition int) string {
+	var sb strings.Builder
+
+	// Add title with consistent styling
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#1E90FF")).
+		Padding(0, 1).
+		Align(lipgloss.Center)
+
+	sb.WriteString(titleStyle.Render(tableName))
+	sb.WriteString("\n\n")
+
+	if ddl == "" {
+		sb.WriteString(fmt.Sprintf("No DDL available for table: %s", tableName))
+		return sb.String()
+	}
+
+	writeSQLLines(&sb, styles, DDLLines(ddl), scrollPosition)
+	return sb.String()
+}
+
+// writeSQLLines writes the visible window of highlighted SQL lines below a title,
+// with indicators for the lines scrolled out of view
+func writeSQLLines(sb *strings.Builder, styles Styles, lines []string, scrollPosition int) {
+	// Overhead: Borders(2), Title(2), scroll indicators(2)
+	maxVisibleRows := max(1, styles.MainBoxStyle.GetHeight()-6)
+	scrollPosition = max(0, min(scrollPosition, len(lines)-1))
+	endPos := min(scrollPosition+maxVisibleRows, len(lines))
+
+	indicatorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA")).
+		Align(lipgloss.Center)
+
+	if scrollPosition > 0 {
+		sb.WriteString(indicatorStyle.Render(fmt.Sprintf("↑ %d more line(s)", scrollPosition)))
+		sb.WriteString("\n")
+	}
+
+	for i := scrollPosition; i < endPos; i++ {
+		sb.WriteString(HighlightSQL(lines[i]))
+		sb.WriteString("\n")
+	}
+
+	if endPos < len(lines) {
+		sb.WriteString(indicatorStyle.Render(fmt.Sprintf("↓ %d more line(s)", len(lines)-endPos)))
+	}
+}
+
+// RenderObject renders a procedure, function, trigger or event: its signature and
+// summary, its parameters and its definition, scrolled by whole lines
+func RenderObject(styles Styles, obj model.SchemaObject, scrollPosition int) string {
+	var sb strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF")).
+		Background(lipgloss.Color("#1E90FF")).
+		Padding(0, 1).
+		Align(lipgloss.Center)
+
+	sb.WriteString(titleStyle.Render(string(obj.Kind) + " " + obj.Name))
+	sb.WriteString("\n\n")
+
+	writeSQLLines(&sb, styles, ObjectLines(obj), scrollPosition)
+	return sb.String()
+}
+
+// ObjectLines lays out a schema object for RenderObject: the signature and summary,
+// one line per routine parameter, then the definition
+func ObjectLines(obj model.SchemaObject) []string {
+	var lines []string
+	routine := obj.Kind == model.ProcedureObject || obj.Kind == model.FunctionObject
+	if routine {
+		lines = append(lines, "-- "+obj.Signature())
+	}
+	if obj.Detail != "" {
+		lines = append(lines, obj.Detail)
+	}
+
+	if routine {
+		lines = append(lines, "", "-- Parameters")
+		if len(obj.Parameters) == 0 {
+			lines = append(lines, "--   (none)")
+		}
+		for _, p := range obj.Parameters {
+			lines = append(lines, fmt.Sprintf("%-6s %-20s %s", p.Mode, p.Name, p.Type))
+		}
+	}
+
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "-- Definition")
+	if obj.Definition == "" {
+		lines = append(lines, "--   (not available)")
+	} else {
+		lines = append(lines, DDLLines(strings.TrimRight(obj.Definition, "\n"))...)
+	}
+	return lines
+}
+
+// DDLLines splits a create statement into lines, expanding tabs so widths are stable
+func DDLLines(ddl string) []string {
+	return strings.Split(strings.ReplaceAll(ddl, "\t", "    "), "\n")
+}
+
 // Helper function to find minimum of two integers
 func min(a, b int) int {
 	if a < b {
@@ -1160,8 +1727,26 @@ func max(a, b int) int {
 	return b
 }
 
-// RenderEditModal renders a floating modal for editing cell data
-func RenderEditModal(styles Styles, termWidth, termHeight int, fieldName, editBuffer string) string {
+// Helper function to find maximum of two int64 values
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// RenderEditModal renders a floating modal for editing cell data, with the error of
+// a rejected value in place of the help line
+func RenderEditModal(styles Styles, termWidth, termHeight int, fieldName, editBuffer, errorMessage string) string {
+	help := "Enter: Save | Esc: Cancel"
+	if errorMessage != "" {
+		help = errorMessage
+	}
+	return RenderPrompt(styles, termWidth, termHeight, fmt.Sprintf("Edit %s", fieldName), editBuffer, help)
+}
+
+// RenderPrompt renders a floating modal with a title, a single-line input and a help line
+func RenderPrompt(styles Styles, termWidth, termHeight int, title, editBuffer, help string) string {
 	// Define modal dimensions (relative to terminal size)
 	modalWidth := min(termWidth-10, 60) // Max 60 chars wide, or less if terminal is small
 	// Simple height for now, could be dynamic later
@@ -1177,7 +1762,6 @@ func RenderEditModal(styles Styles, termWidth, termHeight int, fieldName, editBu
 		Background(lipgloss.Color("#333333")) // Dark background
 
 	// Title
-	title := fmt.Sprintf("Edit %s", fieldName)
 	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
 	titleLine := titleStyle.Render(model.TruncateWithEllipsis(title, modalWidth-4)) // Truncate title if needed
 
@@ -1196,7 +1780,7 @@ func RenderEditModal(styles Styles, termWidth, termHeight int, fieldName, editBu
 
 	// Help text
 	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Italic(true)
-	helpLine := helpStyle.Render("Enter: Save | Esc: Cancel")
+	helpLine := helpStyle.Render(model.TruncateWithEllipsis(help, modalWidth-4))
 
 	// Combine modal content
 	content := lipgloss.JoinVertical(lipgloss.Left,
@@ -1210,3 +1794,155 @@ func RenderEditModal(styles Styles, termWidth, termHeight int, fieldName, editBu
 	// Render the modal box with content
 	return modalStyle.Render(content)
 }
+
+// RenderChangeReview renders a floating modal listing the SQL of all staged changes
+func RenderChangeReview(styles Styles, termWidth, termHeight int, statements []string, scrollPosition int) string {
+	modalWidth := min(termWidth-10, 100)
+	modalHeight := min(termHeight-6, 24)
+
+	modalStyle := lipgloss.NewStyle().
+		Width(modalWidth).
+		Height(modalHeight).
+		Border(lipgloss.DoubleBorder(), true).
+		BorderForeground(styles.ActiveBorderColor).
+		Padding(1, 2).
+		Background(lipgloss.Color("#333333"))
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
+	titleLine := titleStyle.Render(fmt.Sprintf("Pending changes (%d)", len(statements)))
+
+	// Title(1) + Spacer(1) + Spacer(1) + Help(1) + vertical padding(2)
+	maxVisible := modalHeight - 6
+	if maxVisible < 1 {
+		maxVisible = 1
+	}
+	if scrollPosition > len(statements)-maxVisible {
+		scrollPosition = max(0, len(statements)-maxVisible)
+	}
+	endPos := min(scrollPosition+maxVisible, len(statements))
+
+	sqlStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AACCFF"))
+	lines := make([]string, 0, endPos-scrollPosition)
+	for i := scrollPosition; i < endPos; i++ {
+		lines = append(lines, sqlStyle.Render(model.TruncateWithEllipsis(statements[i]+";", modalWidth-4)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No pending changes")
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Italic(true)
+	helpText := "c: Commit | x: Discard all | ↑/↓: Scroll | Esc: Close"
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleLine,
+		"", // Spacer
+		strings.Join(lines, "\n"),
+		"", // Spacer
+		helpStyle.Render(helpText),
+	)
+
+	return modalStyle.Render(content)
+}
+
+// RenderProfilePicker renders the startup screen for choosing a connection profile
+func RenderProfilePicker(styles Styles, termWidth, termHeight int, names, summaries []string, selectedIdx int) string {
+	modalWidth := min(termWidth-10, 80)
+
+	modalStyle := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.DoubleBorder(), true).
+		BorderForeground(styles.ActiveBorderColor).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(styles.ActiveBorderColor)
+	summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+
+	nameWidth := 0
+	for _, name := range names {
+		nameWidth = max(nameWidth, lipgloss.Width(name))
+	}
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		label := fmt.Sprintf(" %-*s ", nameWidth, name)
+		if i == selectedIdx {
+			label = selectedStyle.Render(label)
+		} else {
+			label = nameStyle.Render(label)
+		}
+		summary := model.TruncateWithEllipsis(summaries[i], max(modalWidth-nameWidth-7, 0))
+		lines[i] = label + "  " + summaryStyle.Render(summary)
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Italic(true)
+	helpText := "↑/↓: Select | Enter: Connect | q/Esc: Quit"
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Choose a connection"),
+		"", // Spacer
+		strings.Join(lines, "\n"),
+		"", // Spacer
+		helpStyle.Render(helpText),
+	)
+
+	return lipgloss.Place(termWidth, termHeight, lipgloss.Center, lipgloss.Center, modalStyle.Render(content))
+}
+
+// RenderDatabasePicker renders the modal for switching to another database
+func RenderDatabasePicker(styles Styles, termWidth, termHeight int, databases []string, selectedIdx int, current string) string {
+	modalWidth := min(termWidth-10, 60)
+	modalHeight := min(termHeight-6, 24)
+
+	modalStyle := lipgloss.NewStyle().
+		Width(modalWidth).
+		Height(modalHeight).
+		Border(lipgloss.DoubleBorder(), true).
+		BorderForeground(styles.ActiveBorderColor).
+		Padding(1, 2).
+		Background(lipgloss.Color("#333333"))
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF"))
+	selectedStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(styles.ActiveBorderColor)
+	currentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+
+	// Title(1) + Spacer(1) + Spacer(1) + Help(1) + vertical padding(2)
+	maxVisible := modalHeight - 6
+	if maxVisible < 1 {
+		maxVisible = 1
+	}
+	scrollPosition := 0
+	if selectedIdx >= maxVisible {
+		scrollPosition = selectedIdx - maxVisible + 1
+	}
+	endPos := min(scrollPosition+maxVisible, len(databases))
+
+	lines := make([]string, 0, endPos-scrollPosition)
+	for i := scrollPosition; i < endPos; i++ {
+		label := " " + model.TruncateWithEllipsis(databases[i], modalWidth-16) + " "
+		if i == selectedIdx {
+			label = selectedStyle.Render(label)
+		}
+		if databases[i] == current {
+			label += currentStyle.Render(" (current)")
+		}
+		lines = append(lines, label)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No databases found")
+	}
+
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA")).Italic(true)
+	helpText := "↑/↓: Select | Enter: Switch | Esc: Close"
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(fmt.Sprintf("Databases (%d)", len(databases))),
+		"", // Spacer
+		strings.Join(lines, "\n"),
+		"", // Spacer
+		helpStyle.Render(helpText),
+	)
+
+	return modalStyle.Render(content)
+}
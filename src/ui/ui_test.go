@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/md-salehzadeh/dbun/src/model"
+)
+
+func TestStructureLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata []model.ColumnMetadata
+		want     int
+	}{
+		{"no columns", nil, 0},
+		{"plain columns", []model.ColumnMetadata{{Name: "id"}, {Name: "name"}}, 2},
+		{"comment", []model.ColumnMetadata{{Name: "id"}, {Name: "name", Comment: "display name"}}, 3},
+		{"details share a line", []model.ColumnMetadata{{Name: "name", Collation: "utf8mb4_bin", Comment: "x"}}, 2},
+		{"generated column", []model.ColumnMetadata{{Name: "total", Generated: "qty * price"}, {Name: "qty", Charset: "latin1"}}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StructureLines(tt.metadata); got != tt.want {
+				t.Errorf("StructureLines() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDDLLines(t *testing.T) {
+	tests := []struct {
+		name string
+		ddl  string
+		want []string
+	}{
+		{"one line", "CREATE TABLE t (id INT)", []string{"CREATE TABLE t (id INT)"}},
+		{"tabs are expanded", "CREATE TABLE t (\n\tid INT\n)", []string{"CREATE TABLE t (", "    id INT", ")"}},
+		{"empty", "", []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DDLLines(tt.ddl); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DDLLines(%q) = %q, want %q", tt.ddl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelationsLines(t *testing.T) {
+	keys := func(tables ...string) []model.ForeignKey {
+		var fks []model.ForeignKey
+		for _, table := range tables {
+			fks = append(fks, model.ForeignKey{Name: "fk_" + table, Table: table, Columns: []string{"id"},
+				RefTable: "users", RefColumns: []string{"id"}})
+		}
+		return fks
+	}
+
+	tests := []struct {
+		name     string
+		outgoing []model.ForeignKey
+		incoming []model.ForeignKey
+		wantLine []int // Line of each key, in selection order
+	}{
+		{"outgoing only", keys("a", "b"), nil, []int{1, 2}},
+		{"incoming only", nil, keys("a", "b", "c"), []int{4, 5, 6}},
+		{"both", keys("a"), keys("b", "c"), []int{1, 4, 5}},
+	}
+
+	// Tall enough to show every line, so the lines can be counted in the output
+	styles := NewStyles(200, 100)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := RenderTableRelations(styles, "users", tt.outgoing, tt.incoming, 0, 0)
+			lines := strings.Split(out, "\n")[2:] // Past the title and the blank line after it
+			if got := RelationsLines(tt.outgoing, tt.incoming); got != len(lines) {
+				t.Errorf("RelationsLines() = %d, want %d", got, len(lines))
+			}
+
+			for idx, want := range tt.wantLine {
+				fk := append(append([]model.ForeignKey{}, tt.outgoing...), tt.incoming...)[idx]
+				if got := RelationLine(tt.outgoing, idx); got != want {
+					t.Errorf("RelationLine(%d) = %d, want %d", idx, got, want)
+				} else if !strings.Contains(lines[got], fk.Name) {
+					t.Errorf("line %d = %q, want the key %s", got, lines[got], fk.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		object model.SchemaObject
+		want   []string
+	}{
+		{
+			name: "function",
+			object: model.SchemaObject{Kind: model.FunctionObject, Name: "total", Detail: "RETURNS int",
+				Parameters: []model.RoutineParameter{{Mode: "IN", Name: "id", Type: "int"}}, Definition: "BEGIN\n\tRETURN 1;\nEND\n"},
+			want: []string{"-- total(IN id int)", "RETURNS int", "", "-- Parameters", "IN     id                   int", "",
+				"-- Definition", "BEGIN", "    RETURN 1;", "END"},
+		},
+		{
+			name:   "procedure without parameters or definition",
+			object: model.SchemaObject{Kind: model.ProcedureObject, Name: "cleanup"},
+			want:   []string{"-- cleanup()", "", "-- Parameters", "--   (none)", "", "-- Definition", "--   (not available)"},
+		},
+		{
+			name:   "trigger",
+			object: model.SchemaObject{Kind: model.TriggerObject, Name: "touch", Detail: "ON users", Definition: "CREATE TRIGGER touch"},
+			want:   []string{"ON users", "", "-- Definition", "CREATE TRIGGER touch"},
+		},
+		{
+			name:   "event without detail",
+			object: model.SchemaObject{Kind: model.EventObject, Name: "purge", Definition: "DELETE FROM logs"},
+			want:   []string{"-- Definition", "DELETE FROM logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ObjectLines(tt.object); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ObjectLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}